@@ -6,6 +6,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -14,25 +15,52 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// stopFlushTimeout bounds how long stopping waits for output to flush
+const stopFlushTimeout = 2 * time.Second
+
 // App struct
 type App struct {
-	ctx       context.Context
-	simulator *nmea.Simulator
-	mu        sync.RWMutex
-	isRunning bool
-	mode      string
-	rtzFileOnStartup string
+	ctx               context.Context
+	simulator         *nmea.Simulator
+	mu                sync.RWMutex
+	isRunning         bool
+	mode              string
+	rtzFileOnStartup  string
+	lineTerminator    string
+	acquisitionDelay  time.Duration
+	startProfile      string
+	batchDatagram     bool
+	vessels           map[string]*vessel
+	positionPrecision int
+	loopbackDisabled  bool // keep multicast from receivers on this machine
+	socketPath        string
+	burstCancel       context.CancelFunc // cancels a running burst test
+	capture           *loopbackCapture   // built-in receiver, when enabled
+	altitudeUnit      string
+	routeAtStart      bool
+	routeInterval     time.Duration
+	blankCourse       bool
+	strictASCII       bool
+	lowercaseChecksum bool
+	autoSwapLatLon    bool
+	speedRamp         time.Duration
+	transmitMode      string
+	heartbeat         time.Duration
+	talkerIDs         []string
+	stopOnNoListener  bool
+	nmeaVersion       string
 }
 
 // SimulationStatus represents the current state for frontend
 type SimulationStatus struct {
-	IsRunning       bool                   `json:"isRunning"`
-	Mode            string                 `json:"mode"` // "manual" or "rtz"
-	Position        Position               `json:"position"`
-	Speed           float64                `json:"speed"`
-	Course          float64                `json:"course"`
-	Route           *RTZRoute              `json:"route,omitempty"`
-	WaypointStatus  map[string]interface{} `json:"waypointStatus,omitempty"`
+	IsRunning        bool                   `json:"isRunning"`
+	Mode             string                 `json:"mode"` // "manual" or "rtz"
+	Position         Position               `json:"position"`
+	Speed            float64                `json:"speed"`
+	Course           float64                `json:"course"`
+	Route            *RTZRoute              `json:"route,omitempty"`
+	WaypointStatus   map[string]interface{} `json:"waypointStatus,omitempty"`
+	DistanceTraveled float64                `json:"distanceTraveled"` // NM since the simulation started or stopped or the route was loaded
 }
 
 // Position for JSON serialization
@@ -42,16 +70,49 @@ type Position struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// NavigationState for JSON serialization of the full simulator state
+type NavigationState struct {
+	Position      Position `json:"position"`
+	Speed         float64  `json:"speed"`
+	Course        float64  `json:"course"`  // over the ground
+	Heading       float64  `json:"heading"` // where the bow points; opposite the course when astern
+	Astern        bool     `json:"astern"`
+	MagneticVar   float64  `json:"magneticVar"`
+	FixQuality    int      `json:"fixQuality"`
+	Satellites    int      `json:"satellites"`
+	HDOP          float64  `json:"hdop"`
+	Altitude      float64  `json:"altitude"`
+	CurrentSet    float64  `json:"currentSet"`
+	CurrentDrift  float64  `json:"currentDrift"`
+	DGPSStationID int      `json:"dgpsStationId"`
+	DGPSAge       float64  `json:"dgpsAge"`
+}
+
 // Waypoint for JSON serialization
 type Waypoint struct {
-	ID        string  `json:"id"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
+	ID           string  `json:"id"`
+	Name         string  `json:"name,omitempty"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	SteeringMode string  `json:"steeringMode,omitempty"` // "flyover" or "flyby"
+	Radius       float64 `json:"radius,omitempty"`       // fly-by turn radius, NM
 }
 
 // RTZRoute for JSON serialization
 type RTZRoute struct {
-	Waypoints []Waypoint `json:"waypoints"`
+	Name       string     `json:"name,omitempty"`
+	VesselName string     `json:"vesselName,omitempty"`
+	VesselIMO  string     `json:"vesselIMO,omitempty"`
+	Version    string     `json:"version,omitempty"`
+	Waypoints  []Waypoint `json:"waypoints"`
+}
+
+// RouteMetadata describes the loaded route
+type RouteMetadata struct {
+	Name       string `json:"name"`
+	VesselName string `json:"vesselName"`
+	VesselIMO  string `json:"vesselIMO"`
+	Version    string `json:"version"`
 }
 
 // ManualConfig for manual mode
@@ -64,19 +125,85 @@ type ManualConfig struct {
 
 // RTZConfig for RTZ mode
 type RTZConfig struct {
-	FilePath string  `json:"filePath"`
-	Speed    float64 `json:"speed"`
+	FilePath      string   `json:"filePath"` // local path or http(s) URL
+	Speed         float64  `json:"speed"`
+	InitialCourse *float64 `json:"initialCourse,omitempty"` // optional course held until the first target is reached or passed abeam
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	return &App{
+		lineTerminator:    "\r\n",
+		vessels:           make(map[string]*vessel),
+		positionPrecision: 4,
+		altitudeUnit:      nmea.AltitudeMeters,
+		talkerIDs:         []string{"GP"},
+		nmeaVersion:       nmea.NMEAVersion23,
+	}
+}
+
+// simulatorConfig builds the simulator configuration from the app settings
+func (a *App) simulatorConfig(magneticVar float64) nmea.SimulatorConfig {
+	return nmea.SimulatorConfig{
+		Port:                     defaultPort,
+		TransmitRate:             1 * time.Second,
+		MagneticVar:              magneticVar,
+		LineTerminator:           a.lineTerminator,
+		AcquisitionDelay:         a.acquisitionDelay,
+		BatchDatagram:            a.batchDatagram,
+		PositionPrecision:        a.positionPrecision,
+		DisableMulticastLoopback: a.loopbackDisabled,
+		SocketPath:               a.socketPath,
+		AltitudeUnit:             a.altitudeUnit,
+		RouteAtStart:             a.routeAtStart,
+		RouteInterval:            a.routeInterval,
+		BlankCourseWhenStopped:   a.blankCourse,
+		StrictASCII:              a.strictASCII,
+		LowercaseChecksum:        a.lowercaseChecksum,
+		TalkerIDs:                a.talkerIDs,
+		StopOnNoListener:         a.stopOnNoListener,
+		NMEAVersion:              a.nmeaVersion,
+		AutoSwapLatLon:           a.autoSwapLatLon,
+		SpeedRamp:                a.speedRamp,
+		TransmitMode:             a.transmitMode,
+		Heartbeat:                a.heartbeat,
+		StartProfile:             a.startProfile,
+	}
 }
 
 // OnStartup is called when the app starts up
 func (a *App) OnStartup(ctx context.Context) {
 	a.ctx = ctx
 	a.mode = "manual"
+
+	go a.watchdog(ctx)
+}
+
+// watchdog notifies the frontend when a running simulation stops transmitting,
+// and again when it recovers
+func (a *App) watchdog(ctx context.Context) {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	stalled := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.mu.RLock()
+			running := a.isRunning && a.simulator != nil
+			healthy := !running || a.simulator.IsHealthy()
+			a.mu.RUnlock()
+
+			if !healthy && !stalled {
+				runtime.EventsEmit(ctx, "simulationStalled")
+			} else if healthy && stalled {
+				runtime.EventsEmit(ctx, "simulationRecovered")
+			}
+			stalled = !healthy
+		}
+	}
 }
 
 // OnDomReady is called after front-end resources have been loaded
@@ -98,6 +225,16 @@ func (a *App) OnShutdown(ctx context.Context) {
 	if a.simulator != nil {
 		a.simulator.Close()
 	}
+	a.closeFleet()
+
+	a.mu.Lock()
+	if a.burstCancel != nil {
+		a.burstCancel()
+	}
+	if a.capture != nil {
+		a.capture.close()
+	}
+	a.mu.Unlock()
 }
 
 // StartManualSimulation starts simulation with manual parameters
@@ -106,7 +243,7 @@ func (a *App) StartManualSimulation(config ManualConfig) error {
 	defer a.mu.Unlock()
 
 	if a.isRunning {
-		return fmt.Errorf("simulation is already running")
+		return nmea.ErrAlreadyRunning
 	}
 
 	// Stop existing simulator if any
@@ -115,11 +252,7 @@ func (a *App) StartManualSimulation(config ManualConfig) error {
 	}
 
 	// Create new simulator
-	simConfig := nmea.SimulatorConfig{
-		Port:         10110,
-		TransmitRate: 1 * time.Second,
-		MagneticVar:  -5.0,
-	}
+	simConfig := a.simulatorConfig(-5.0)
 
 	var err error
 	a.simulator, err = nmea.NewSimulator(simConfig)
@@ -145,87 +278,875 @@ func (a *App) StartRTZSimulation(config RTZConfig) error {
 	defer a.mu.Unlock()
 
 	if a.isRunning {
-		return fmt.Errorf("simulation is already running")
+		return nmea.ErrAlreadyRunning
+	}
+
+	// Read RTZ file or download it
+	rtzData, err := readRTZSource(config.FilePath)
+	if err != nil {
+		return err
+	}
+
+	return a.startRouteSimulation(config, func(sim *nmea.Simulator) error {
+		if err := sim.LoadRTZRoute(rtzData, config.Speed); err != nil {
+			return fmt.Errorf("failed to load RTZ route: %w", err)
+		}
+		return nil
+	})
+}
+
+// startRouteSimulation builds a simulator, loads a route into it with load and
+// starts it in RTZ mode. The previous simulator is only replaced once the
+// route has loaded, so a route that fails leaves it in place. The caller must
+// hold the write lock
+func (a *App) startRouteSimulation(config RTZConfig, load func(sim *nmea.Simulator) error) error {
+	sim, err := nmea.NewSimulator(a.simulatorConfig(-3.0))
+	if err != nil {
+		return fmt.Errorf("failed to create simulator: %w", err)
+	}
+
+	if err := load(sim); err != nil {
+		sim.Close()
+		return err
+	}
+
+	// Start on the requested course, held until the first waypoint is reached
+	// or passed abeam before steering onto the route
+	if config.InitialCourse != nil {
+		sim.SetInitialCourse(*config.InitialCourse)
 	}
 
-	// Stop existing simulator if any
 	if a.simulator != nil {
 		a.simulator.Close()
 	}
+	a.simulator = sim
+
+	if err := a.simulator.Start(); err != nil {
+		return fmt.Errorf("failed to start simulator: %w", err)
+	}
+
+	a.isRunning = true
+	a.mode = "rtz"
+	return nil
+}
 
-	// Read RTZ file
-	rtzData, err := os.ReadFile(config.FilePath)
+// StartRTZSimulationFromURL starts RTZ simulation with a route downloaded
+// from an http(s) URL
+func (a *App) StartRTZSimulationFromURL(url string, speed float64) error {
+	if !isRTZURL(url) {
+		return fmt.Errorf("route URL must start with http:// or https://")
+	}
+
+	return a.StartRTZSimulation(RTZConfig{FilePath: url, Speed: speed})
+}
+
+// StartCSVSimulation starts route simulation with waypoints from a CSV file of
+// id,lat,lon rows. The route runs in RTZ mode, so all waypoint controls apply
+func (a *App) StartCSVSimulation(config RTZConfig) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.isRunning {
+		return nmea.ErrAlreadyRunning
+	}
+
+	csvData, err := os.ReadFile(config.FilePath)
 	if err != nil {
-		return fmt.Errorf("failed to read RTZ file: %w", err)
+		return fmt.Errorf("failed to read CSV file: %w", err)
 	}
 
-	// Create new simulator
-	simConfig := nmea.SimulatorConfig{
-		Port:         10110,
-		TransmitRate: 1 * time.Second,
-		MagneticVar:  -3.0,
+	route, err := nmea.ParseCSVRoute(csvData)
+	if err != nil {
+		return fmt.Errorf("failed to load CSV route: %w", err)
 	}
 
-	a.simulator, err = nmea.NewSimulator(simConfig)
+	return a.startRouteSimulation(config, func(sim *nmea.Simulator) error {
+		if err := sim.SetRoute(route, config.Speed); err != nil {
+			return fmt.Errorf("failed to load CSV route: %w", err)
+		}
+		return nil
+	})
+}
+
+// StartRTZFromCurrentPosition starts RTZ simulation from the vessel's current
+// position, transiting to the first waypoint before following the route
+func (a *App) StartRTZFromCurrentPosition(config RTZConfig) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.isRunning {
+		return nmea.ErrAlreadyRunning
+	}
+
+	if a.simulator == nil {
+		return fmt.Errorf("no current position available - run a manual simulation first")
+	}
+
+	// Read RTZ file or download it
+	rtzData, err := readRTZSource(config.FilePath)
 	if err != nil {
-		return fmt.Errorf("failed to create simulator: %w", err)
+		return err
 	}
 
-	// Load route and start
-	if err := a.simulator.LoadRTZRoute(rtzData, config.Speed); err != nil {
-		return fmt.Errorf("failed to load RTZ route: %w", err)
+	// Restore position, then load route targeting the first waypoint
+	current := a.simulator.GetCurrentState()
+	return a.startRouteSimulation(config, func(sim *nmea.Simulator) error {
+		sim.SetPosition(current.Position.Latitude, current.Position.Longitude, config.Speed, current.Course)
+		if err := sim.LoadRTZRouteFromPosition(rtzData, config.Speed); err != nil {
+			return fmt.Errorf("failed to load RTZ route: %w", err)
+		}
+		return nil
+	})
+}
+
+// StopSimulation stops the current simulation
+func (a *App) StopSimulation() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.isRunning {
+		return nmea.ErrNotRunning
 	}
 
-	if err := a.simulator.Start(); err != nil {
-		return fmt.Errorf("failed to start simulator: %w", err)
+	a.isRunning = false
+
+	if a.simulator != nil {
+		// Let the current transmission finish rather than cutting it off
+		ctx, cancel := context.WithTimeout(context.Background(), stopFlushTimeout)
+		defer cancel()
+		return a.simulator.StopAndFlush(ctx)
+	}
+
+	return nil
+}
+
+// SetLineTerminator sets the sentence terminator ("\r\n", "\n" or "\r"),
+// applying it to the running simulation and any future one
+func (a *App) SetLineTerminator(terminator string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := nmea.ValidateLineTerminator(terminator); err != nil {
+		return err
+	}
+
+	if a.simulator != nil {
+		a.simulator.SetLineTerminator(terminator)
+	}
+
+	a.lineTerminator = terminator
+	return nil
+}
+
+// SetAcquisitionDelay sets how many seconds after starting a simulation the
+// feed reports no fix before acquiring one
+func (a *App) SetAcquisitionDelay(seconds int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if seconds < 0 {
+		return fmt.Errorf("acquisition delay cannot be negative")
+	}
+
+	if a.simulator != nil {
+		a.simulator.SetAcquisitionDelay(seconds)
+	}
+
+	a.acquisitionDelay = time.Duration(seconds) * time.Second
+	return nil
+}
+
+// SetStartProfile sets how a simulation acquires its fix after starting:
+// "cold", "warm" or "hot", or "" to use the acquisition delay alone
+func (a *App) SetStartProfile(profile string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := nmea.ValidateStartProfile(profile); err != nil {
+		return err
+	}
+
+	if a.simulator != nil {
+		a.simulator.SetStartProfile(profile)
+	}
+
+	a.startProfile = profile
+	return nil
+}
+
+// SetSpeedRamp sets how many seconds the vessel takes to reach its speed from
+// rest when a simulation starts. Zero starts at full speed
+func (a *App) SetSpeedRamp(seconds int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ramp := time.Duration(seconds) * time.Second
+	if err := nmea.ValidateSpeedRamp(ramp); err != nil {
+		return err
+	}
+
+	if a.simulator != nil {
+		a.simulator.SetSpeedRamp(ramp)
+	}
+
+	a.speedRamp = ramp
+	return nil
+}
+
+// SetBatchDatagram sets whether each transmit cycle is sent as one datagram
+func (a *App) SetBatchDatagram(batch bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.simulator != nil {
+		a.simulator.SetBatchDatagram(batch)
+	}
+
+	a.batchDatagram = batch
+}
+
+// SetSocketPath sets a Unix datagram socket to send the feed to instead of UDP,
+// or "" for UDP. Takes effect when the next simulation starts
+func (a *App) SetSocketPath(path string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.socketPath = path
+}
+
+// SetMulticastLoopback sets whether multicast sentences are also delivered to
+// listeners on this machine
+func (a *App) SetMulticastLoopback(enabled bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.simulator != nil {
+		if err := a.simulator.SetMulticastLoopback(enabled); err != nil {
+			return err
+		}
+	}
+
+	a.loopbackDisabled = !enabled
+	return nil
+}
+
+// SetPositionPrecision sets the decimal places of minutes in transmitted positions
+func (a *App) SetPositionPrecision(decimals int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if decimals < nmea.MinPositionPrecision || decimals > nmea.MaxPositionPrecision {
+		return fmt.Errorf("position precision must be between %d and %d",
+			nmea.MinPositionPrecision, nmea.MaxPositionPrecision)
+	}
+
+	if a.simulator != nil {
+		a.simulator.SetPositionPrecision(decimals)
+	}
+
+	a.positionPrecision = decimals
+	return nil
+}
+
+// SetRouteBroadcast sets whether the route is transmitted as WPL sentences
+// when the simulation starts, and how often it is repeated (0 for never)
+func (a *App) SetRouteBroadcast(atStart bool, intervalSeconds int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if intervalSeconds < 0 {
+		return fmt.Errorf("route broadcast interval cannot be negative")
+	}
+	interval := time.Duration(intervalSeconds) * time.Second
+
+	if a.simulator != nil {
+		a.simulator.SetRouteBroadcast(atStart, interval)
+	}
+
+	a.routeAtStart = atStart
+	a.routeInterval = interval
+	return nil
+}
+
+// SetBlankCourseWhenStopped sets whether RMC and VTG leave the course empty
+// while the vessel is stopped
+func (a *App) SetBlankCourseWhenStopped(blank bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.simulator != nil {
+		a.simulator.SetBlankCourseWhenStopped(blank)
+	}
+
+	a.blankCourse = blank
+}
+
+// SetTalkerIDs sets the talker IDs each GNSS fix sentence is sent under, e.g.
+// ["GP", "GN"] to send both GPRMC and GNRMC
+func (a *App) SetTalkerIDs(ids []string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := nmea.ValidateTalkerIDs(ids); err != nil {
+		return err
+	}
+
+	if a.simulator != nil {
+		if err := a.simulator.SetTalkerIDs(ids); err != nil {
+			return err
+		}
+	}
+
+	a.talkerIDs = append([]string(nil), ids...)
+	return nil
+}
+
+// SetStopOnNoListener sets whether transmission stops when nothing is
+// listening on the destination port, instead of retrying until a listener appears
+func (a *App) SetStopOnNoListener(stop bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.simulator != nil {
+		a.simulator.SetStopOnNoListener(stop)
+	}
+
+	a.stopOnNoListener = stop
+}
+
+// SetTransmitMode sets whether sentences are sent every cycle ("periodic") or
+// only when their values change ("onchange"). In change-driven mode an
+// unchanged sentence is still sent every heartbeatSeconds
+func (a *App) SetTransmitMode(mode string, heartbeatSeconds int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := nmea.ValidateTransmitMode(mode); err != nil {
+		return err
+	}
+	if heartbeatSeconds <= 0 {
+		return fmt.Errorf("heartbeat must be positive")
+	}
+	heartbeat := time.Duration(heartbeatSeconds) * time.Second
+
+	if a.simulator != nil {
+		a.simulator.SetTransmitMode(mode)
+		a.simulator.SetHeartbeat(heartbeat)
+	}
+
+	a.transmitMode = mode
+	a.heartbeat = heartbeat
+	return nil
+}
+
+// SetNMEAVersion sets the NMEA 0183 version ("2.1" or "2.3") whose sentence
+// layout is followed
+func (a *App) SetNMEAVersion(version string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := nmea.ValidateNMEAVersion(version); err != nil {
+		return err
+	}
+
+	if a.simulator != nil {
+		a.simulator.SetNMEAVersion(version)
+	}
+
+	a.nmeaVersion = version
+	return nil
+}
+
+// SetStrictASCII sets whether proprietary sentences with non-ASCII or reserved
+// characters are rejected rather than having those characters stripped
+func (a *App) SetStrictASCII(strict bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.simulator != nil {
+		a.simulator.SetStrictASCII(strict)
+	}
+
+	a.strictASCII = strict
+}
+
+// SetLowercaseChecksum sets whether sentence checksums are written in
+// lowercase hex rather than the usual uppercase
+func (a *App) SetLowercaseChecksum(lowercase bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.simulator != nil {
+		a.simulator.SetLowercaseChecksum(lowercase)
+	}
+
+	a.lowercaseChecksum = lowercase
+}
+
+// SetAutoSwapLatLon sets whether RTZ waypoints with latitude and longitude
+// apparently swapped are corrected on loading. Affects routes loaded afterwards
+func (a *App) SetAutoSwapLatLon(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.simulator != nil {
+		a.simulator.SetAutoSwapLatLon(enabled)
+	}
+
+	a.autoSwapLatLon = enabled
+}
+
+// SetAltitudeUnit sets the GGA altitude unit ("m" or "ft")
+func (a *App) SetAltitudeUnit(unit string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := nmea.ValidateAltitudeUnit(unit); err != nil {
+		return err
+	}
+
+	if a.simulator != nil {
+		a.simulator.SetAltitudeUnit(unit)
+	}
+
+	a.altitudeUnit = unit
+	return nil
+}
+
+// UpdateSpeed updates the simulation speed
+func (a *App) UpdateSpeed(speed float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	a.simulator.UpdateSpeed(speed)
+	return nil
+}
+
+// SetAstern sets the vessel moving astern at knots, keeping its heading, for
+// docking and maneuvering scenarios
+func (a *App) SetAstern(knots float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetAstern(knots)
+}
+
+// UpdateCourse updates the simulation course (manual mode only)
+func (a *App) UpdateCourse(course float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	a.simulator.UpdateCourse(course)
+	return nil
+}
+
+// SetMultipathMode enables or disables simulated multipath position jumps
+func (a *App) SetMultipathMode(enabled bool, jumpProbability float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetMultipathMode(enabled, jumpProbability)
+}
+
+// SetSatelliteCount sets the number of satellites in view
+func (a *App) SetSatelliteCount(count int) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetSatelliteCount(count)
+}
+
+// SimulateSatelliteFault flags a satellite as faulty in GBS reports (0 clears)
+func (a *App) SimulateSatelliteFault(prn int) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SimulateSatelliteFault(prn)
+}
+
+// SetFixQuality sets the reported GGA fix quality (0-8)
+func (a *App) SetFixQuality(quality int) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetFixQuality(quality)
+}
+
+// ScheduleFixQualityChange switches the fix quality after delaySeconds, to
+// test how displays react to a fix type transition
+func (a *App) ScheduleFixQualityChange(delaySeconds int, quality int) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	if delaySeconds < 0 {
+		return fmt.Errorf("delay cannot be negative")
+	}
+
+	// Schedule in GPS time, which carries any simulated clock offset
+	at := a.simulator.GPSTime().Add(time.Duration(delaySeconds) * time.Second)
+	return a.simulator.ScheduleFixQualityChange(at, quality)
+}
+
+// SetDGPSCorrection sets the DGPS station ID and correction age
+func (a *App) SetDGPSCorrection(stationID int, age float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetDGPSCorrection(stationID, age)
+}
+
+// SetDynamicSky enables or disables dynamic satellite signal variation
+func (a *App) SetDynamicSky(enabled bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	a.simulator.SetDynamicSky(enabled)
+	return nil
+}
+
+// SetSkyEvolution enables or disables satellites rising and setting over the
+// hours of a long run
+func (a *App) SetSkyEvolution(enabled bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	a.simulator.SetSkyEvolution(enabled)
+	return nil
+}
+
+// AddProprietarySentence adds a proprietary sentence template to the feed
+func (a *App) AddProprietarySentence(template string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.AddProprietarySentence(template)
+}
+
+// ClearProprietarySentences removes all proprietary sentence templates
+func (a *App) ClearProprietarySentences() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	a.simulator.ClearProprietarySentences()
+	return nil
+}
+
+// EnableSimulationMarker sets whether a $PSIMU sentence marking the feed as
+// simulated is sent, so it can't be mistaken for live navigation data
+func (a *App) EnableSimulationMarker(enabled bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	a.simulator.EnableSimulationMarker(enabled)
+	return nil
+}
+
+// EnableDecimalDegreesDebugSentence sets whether a $PSIMDD sentence with the
+// position in signed decimal degrees is sent for easier reading of logs
+func (a *App) EnableDecimalDegreesDebugSentence(enabled bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	a.simulator.EnableDecimalDegreesDebugSentence(enabled)
+	return nil
+}
+
+// SetCurrent sets the water current (direction toward in degrees, speed in knots)
+func (a *App) SetCurrent(set, drift float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	a.simulator.SetCurrent(set, drift)
+	return nil
+}
+
+// SetDeviationTable sets the compass deviation (degrees, east positive) by
+// magnetic heading for HDG output
+func (a *App) SetDeviationTable(table map[int]float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetDeviationTable(table)
+}
+
+// SetGNSSSystems shares the satellites in view between GNSS systems (1 GPS,
+// 2 GLONASS, 3 Galileo, 4 BeiDou), sending a GSA sentence per system with its
+// NMEA 4.10 system ID. An empty list restores the single GPS GSA sentence
+func (a *App) SetGNSSSystems(systems []int) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetGNSSSystems(systems)
+}
+
+// SetConstellationMix sets how many GPS, GLONASS, Galileo and BeiDou
+// satellites are in view, each system reporting its own GSV sentences
+func (a *App) SetConstellationMix(gps, glonass, galileo, beidou int) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetConstellationMix(gps, glonass, galileo, beidou)
+}
+
+// SetFixStatusMapping overrides the RMC/GLL status ("A" or "V") reported for
+// GGA fix qualities 0-8, to reproduce a particular receiver's output
+func (a *App) SetFixStatusMapping(mapping map[int]string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	statuses := make(map[int]rune, len(mapping))
+	for quality, status := range mapping {
+		if len(status) != 1 {
+			return fmt.Errorf("status for fix quality %d must be \"A\" or \"V\"", quality)
+		}
+		statuses[quality] = rune(status[0])
+	}
+
+	return a.simulator.SetFixStatusMapping(statuses)
+}
+
+// SetEngineModel enables RPM output with the shaft at maxRPM when making
+// maxSpeed knots through the water. A maxRPM of zero disables it
+func (a *App) SetEngineModel(maxRPM, maxSpeed float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetEngineModel(maxRPM, maxSpeed)
+}
+
+// SetClockOffset offsets transmitted GPS time from the system clock by
+// offsetSeconds, drifting further by driftPPM parts per million
+func (a *App) SetClockOffset(offsetSeconds float64, driftPPM float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	offset := time.Duration(offsetSeconds * float64(time.Second))
+	return a.simulator.SetClockOffset(offset, driftPPM)
+}
+
+// GetSpeeds returns speed over ground and speed through water
+func (a *App) GetSpeeds() (nmea.Speeds, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return nmea.Speeds{}, nmea.ErrNotRunning
+	}
+
+	return a.simulator.GetSpeeds(), nil
+}
+
+// GetRouteMetadata returns the name, vessel and RTZ version of the loaded route
+func (a *App) GetRouteMetadata() (RouteMetadata, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return RouteMetadata{}, nmea.ErrNotRunning
+	}
+
+	route := a.simulator.GetRoute()
+	if route == nil {
+		return RouteMetadata{}, nmea.ErrNoRoute
+	}
+
+	return RouteMetadata{
+		Name:       route.Name,
+		VesselName: route.VesselName,
+		VesselIMO:  route.VesselIMO,
+		Version:    route.Version,
+	}, nil
+}
+
+// predictorInterval is how far ahead in time the default course predictor reaches
+const predictorInterval = 6 * time.Minute
+
+// GetHeadingVector returns the end point of a course predictor line drawn
+// lengthNM ahead of the vessel. A length of zero gives the distance covered
+// in six minutes at the current speed
+func (a *App) GetHeadingVector(lengthNM float64) (map[string]interface{}, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return nil, nmea.ErrNotRunning
+	}
+
+	if lengthNM < 0 {
+		return nil, fmt.Errorf("predictor length cannot be negative")
+	}
+	if lengthNM == 0 {
+		lengthNM = a.simulator.GetCurrentState().Speed * predictorInterval.Hours()
+	}
+
+	endLat, endLon := a.simulator.PositionAhead(lengthNM)
+	return map[string]interface{}{
+		"endLat":   endLat,
+		"endLon":   endLon,
+		"lengthNM": lengthNM,
+	}, nil
+}
+
+// GetSteeringDiagnostics returns the desired course, cross-track error and
+// correction behind the last steering decision
+func (a *App) GetSteeringDiagnostics() (nmea.SteeringDiagnostics, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return nmea.SteeringDiagnostics{}, nmea.ErrNotRunning
 	}
 
-	a.isRunning = true
-	a.mode = "rtz"
-	return nil
+	return a.simulator.GetSteeringDiagnostics(), nil
 }
 
-// StopSimulation stops the current simulation
-func (a *App) StopSimulation() error {
-	a.mu.Lock()
-	defer a.mu.Unlock()
+// GetOdometer returns the distance run since the simulation was last started,
+// stopped or given a route, in nautical miles
+func (a *App) GetOdometer() (float64, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 
-	if !a.isRunning {
-		return fmt.Errorf("no simulation is running")
+	if a.simulator == nil {
+		return 0, nmea.ErrNotRunning
 	}
 
-	if a.simulator != nil {
-		a.simulator.Stop()
+	return a.simulator.GetDistanceTraveled(), nil
+}
+
+// SetOdometerCountsAstern sets whether distance run astern counts on the
+// odometer
+func (a *App) SetOdometerCountsAstern(count bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
 	}
 
-	a.isRunning = false
+	a.simulator.SetOdometerCountsAstern(count)
 	return nil
 }
 
-// UpdateSpeed updates the simulation speed
-func (a *App) UpdateSpeed(speed float64) error {
+// GetRecentSentences returns up to n of the most recently transmitted sentences
+func (a *App) GetRecentSentences(n int) []string {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
-	if !a.isRunning || a.simulator == nil {
-		return fmt.Errorf("no simulation is running")
+	if a.simulator == nil {
+		return []string{}
 	}
 
-	a.simulator.UpdateSpeed(speed)
-	return nil
+	return a.simulator.GetRecentSentences(n)
 }
 
-// UpdateCourse updates the simulation course (manual mode only)
-func (a *App) UpdateCourse(course float64) error {
+// GetHealth returns whether the feed is live, when it last transmitted and the
+// last transmission error
+func (a *App) GetHealth() map[string]interface{} {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
-	if !a.isRunning || a.simulator == nil {
-		return fmt.Errorf("no simulation is running")
+	health := map[string]interface{}{
+		"healthy": false,
 	}
 
-	a.simulator.UpdateCourse(course)
-	return nil
+	if a.simulator == nil {
+		return health
+	}
+
+	health["healthy"] = a.isRunning && a.simulator.IsHealthy()
+	health["lastTransmit"] = a.simulator.LastTransmit()
+	if err := a.simulator.LastError(); err != nil {
+		health["lastError"] = err.Error()
+	}
+
+	return health
 }
 
 // GetStatus returns the current simulation status
@@ -250,38 +1171,16 @@ func (a *App) GetStatus() (SimulationStatus, error) {
 		}
 		status.Speed = state.Speed
 		status.Course = state.Course
+		status.DistanceTraveled = a.simulator.GetDistanceTraveled()
 
 		// Convert route if available
 		route := a.simulator.GetRoute()
 		if route != nil {
-			status.Route = &RTZRoute{
-				Waypoints: make([]Waypoint, len(route.Waypoints)),
-			}
-			for i, wp := range route.Waypoints {
-				status.Route.Waypoints[i] = Waypoint{
-					ID:        wp.ID,
-					Latitude:  wp.Latitude,
-					Longitude: wp.Longitude,
-				}
-			}
+			status.Route = routeToJSON(route)
 
 			// Add waypoint status for RTZ mode
 			if a.mode == "rtz" {
-				info := a.simulator.GetWaypointInfo()
-				status.WaypointStatus = map[string]interface{}{
-					"currentWaypoint":   info.CurrentWaypoint,
-					"totalWaypoints":    info.TotalWaypoints,
-					"autoNavigate":      info.AutoNavigate,
-					"distanceToTarget":  info.DistanceToTarget,
-				}
-
-				if info.TargetWaypoint != nil {
-					status.WaypointStatus["targetWaypoint"] = map[string]interface{}{
-						"id":        info.TargetWaypoint.ID,
-						"latitude":  info.TargetWaypoint.Latitude,
-						"longitude": info.TargetWaypoint.Longitude,
-					}
-				}
+				status.WaypointStatus = waypointStatusMap(a.simulator.GetWaypointInfo())
 			}
 		}
 	}
@@ -289,6 +1188,38 @@ func (a *App) GetStatus() (SimulationStatus, error) {
 	return status, nil
 }
 
+// GetNavigationState returns the full navigation state of the simulator
+func (a *App) GetNavigationState() (NavigationState, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return NavigationState{}, nmea.ErrNotRunning
+	}
+
+	state := a.simulator.GetCurrentState()
+	return NavigationState{
+		Position: Position{
+			Latitude:  state.Position.Latitude,
+			Longitude: state.Position.Longitude,
+			Timestamp: state.Position.Timestamp,
+		},
+		Speed:         state.Speed,
+		Course:        state.Course,
+		Heading:       a.simulator.GetHeading(),
+		Astern:        a.simulator.IsAstern(),
+		MagneticVar:   state.MagneticVar,
+		FixQuality:    state.FixQuality,
+		Satellites:    state.Satellites,
+		HDOP:          state.HDOP,
+		Altitude:      state.Altitude,
+		CurrentSet:    state.CurrentSet,
+		CurrentDrift:  state.CurrentDrift,
+		DGPSStationID: state.DGPSStationID,
+		DGPSAge:       state.DGPSAge,
+	}, nil
+}
+
 // OpenFileDialog opens a file dialog to select RTZ file
 func (a *App) OpenFileDialog() (string, error) {
 	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
@@ -372,7 +1303,7 @@ func (a *App) ValidateRTZFile(filePath string) (map[string]interface{}, error) {
 		} `xml:"waypoints"`
 	}
 
-	if err := xml.Unmarshal(data, &rtz); err != nil {
+	if err := xml.Unmarshal(nmea.TrimRTZPrefix(data), &rtz); err != nil {
 		return nil, fmt.Errorf("invalid RTZ file format: %w", err)
 	}
 
@@ -385,11 +1316,37 @@ func (a *App) ValidateRTZFile(filePath string) (map[string]interface{}, error) {
 		return nil, fmt.Errorf("RTZ file contains no waypoints")
 	}
 
-	// Validate that waypoints have positions
+	// Validate that waypoints have positions, collecting per-waypoint warnings
 	validWaypoints := 0
-	for _, wp := range rtz.Waypoints.Waypoint {
+	warnings := []map[string]interface{}{}
+	addWarning := func(index int, id, reason string) {
+		warnings = append(warnings, map[string]interface{}{
+			"index":  index,
+			"id":     id,
+			"reason": reason,
+		})
+	}
+
+	for i, wp := range rtz.Waypoints.Waypoint {
 		if wp.Position.Lat != 0 || wp.Position.Lon != 0 {
 			validWaypoints++
+		} else {
+			addWarning(i, wp.ID, "missing position")
+		}
+
+		if nmea.CoordinatesSwapped(wp.Position.Lat, wp.Position.Lon) {
+			addWarning(i, wp.ID, fmt.Sprintf("latitude %.6f and longitude %.6f appear swapped", wp.Position.Lat, wp.Position.Lon))
+		} else if wp.Position.Lat < -90 || wp.Position.Lat > 90 {
+			addWarning(i, wp.ID, fmt.Sprintf("latitude %.6f out of range", wp.Position.Lat))
+		}
+		if wp.Position.Lon < -180 || wp.Position.Lon > 180 {
+			addWarning(i, wp.ID, fmt.Sprintf("longitude %.6f out of range", wp.Position.Lon))
+		}
+		if wp.Name == "" {
+			addWarning(i, wp.ID, "missing name")
+		}
+		if radius, err := strconv.ParseFloat(wp.Radius, 64); wp.Radius != "" && (err != nil || radius <= 0) {
+			addWarning(i, wp.ID, fmt.Sprintf("invalid turn radius %q", wp.Radius))
 		}
 	}
 
@@ -408,6 +1365,7 @@ func (a *App) ValidateRTZFile(filePath string) (map[string]interface{}, error) {
 		"validPositions": validWaypoints,
 		"fileSize":       len(data),
 		"filePath":       filePath,
+		"warnings":       warnings,
 	}
 
 	// Add first and last waypoint info for reference
@@ -437,12 +1395,12 @@ func (a *App) ValidateRTZFile(filePath string) (map[string]interface{}, error) {
 // GetSimulatorInfo returns basic information about the simulator
 func (a *App) GetSimulatorInfo() map[string]interface{} {
 	return map[string]interface{}{
-		"version":   "1.0.0",
+		"version":   nmea.Version,
 		"host":      "127.0.0.1",
 		"port":      10110,
 		"protocol":  "UDP",
 		"format":    "NMEA 0183",
-		"sentences": []string{"GGA", "RMC", "GLL", "VTG", "GSA", "GSV"},
+		"sentences": []string{"GGA", "RMC", "GLL", "VTG", "GSA", "GSV", "VBW", "GBS", "HDG", "RPM", "WPL", "RTE", "PSIMU", "PSIMDD", "VDM"},
 	}
 }
 
@@ -452,18 +1410,14 @@ func (a *App) AdvanceWaypoint() error {
 	defer a.mu.RUnlock()
 
 	if !a.isRunning || a.simulator == nil {
-		return fmt.Errorf("no simulation is running")
+		return nmea.ErrNotRunning
 	}
 
 	if a.mode != "rtz" {
 		return fmt.Errorf("waypoint navigation only available in RTZ mode")
 	}
 
-	if !a.simulator.AdvanceToNextWaypoint() {
-		return fmt.Errorf("cannot advance waypoint - already at last waypoint or no route loaded")
-	}
-
-	return nil
+	return a.simulator.AdvanceToNextWaypoint()
 }
 
 // PreviousWaypoint goes back to the previous waypoint in RTZ mode
@@ -472,18 +1426,31 @@ func (a *App) PreviousWaypoint() error {
 	defer a.mu.RUnlock()
 
 	if !a.isRunning || a.simulator == nil {
-		return fmt.Errorf("no simulation is running")
+		return nmea.ErrNotRunning
 	}
 
 	if a.mode != "rtz" {
 		return fmt.Errorf("waypoint navigation only available in RTZ mode")
 	}
 
-	if !a.simulator.GoToPreviousWaypoint() {
-		return fmt.Errorf("cannot go to previous waypoint - already at first waypoint or no route loaded")
+	return a.simulator.GoToPreviousWaypoint()
+}
+
+// SetWaypointDwell sets how many seconds the vessel holds at a waypoint of the
+// whole route on reaching it before carrying on, in RTZ mode
+func (a *App) SetWaypointDwell(index int, seconds float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
 	}
 
-	return nil
+	if a.mode != "rtz" {
+		return fmt.Errorf("waypoint navigation only available in RTZ mode")
+	}
+
+	return a.simulator.SetWaypointDwell(index, seconds)
 }
 
 // SetWaypoint jumps to a specific waypoint in RTZ mode
@@ -492,51 +1459,388 @@ func (a *App) SetWaypoint(waypointIndex int) error {
 	defer a.mu.RUnlock()
 
 	if !a.isRunning || a.simulator == nil {
-		return fmt.Errorf("no simulation is running")
+		return nmea.ErrNotRunning
+	}
+
+	if a.mode != "rtz" {
+		return fmt.Errorf("waypoint navigation only available in RTZ mode")
+	}
+
+	return a.simulator.SetCurrentWaypoint(waypointIndex)
+}
+
+// SetAutoNavigate enables or disables automatic route following in RTZ mode,
+// allowing manual steering while the route stays loaded
+func (a *App) SetAutoNavigate(enabled bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
 	}
 
 	if a.mode != "rtz" {
 		return fmt.Errorf("waypoint navigation only available in RTZ mode")
 	}
 
-	if !a.simulator.SetCurrentWaypoint(waypointIndex) {
-		return fmt.Errorf("invalid waypoint index or no route loaded")
+	return a.simulator.SetAutoNavigate(enabled)
+}
+
+// AddAISBaseStation adds a simulated AIS base station at a fixed position,
+// for testing how AIS displays handle non-vessel targets
+func (a *App) AddAISBaseStation(mmsi string, lat, lon float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.AddAISBaseStation(mmsi, lat, lon)
+}
+
+// AddAISAidToNavigation adds a simulated AIS aid to navigation at a fixed
+// position
+func (a *App) AddAISAidToNavigation(mmsi, name string, lat, lon float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.AddAISAidToNavigation(mmsi, name, lat, lon)
+}
+
+// ClearAISStations removes all simulated AIS stations
+func (a *App) ClearAISStations() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	a.simulator.ClearAISStations()
+	return nil
+}
+
+// SetSentenceLengthMode sets how sentences over the NMEA 82-character limit
+// are handled: "truncate", "warn" (the default) or "allow"
+func (a *App) SetSentenceLengthMode(mode string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetSentenceLengthMode(mode)
+}
+
+// GetSentenceLengthReport returns the over-length sentences generated so far
+func (a *App) GetSentenceLengthReport() (nmea.SentenceLengthReport, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return nmea.SentenceLengthReport{}, nmea.ErrNotRunning
+	}
+
+	return a.simulator.GetSentenceLengthReport(), nil
+}
+
+// SetSentenceTimeSkew sets whether sentences within a cycle carry slightly
+// different timestamps, like a real multiplexed feed
+func (a *App) SetSentenceTimeSkew(enabled bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	a.simulator.SetSentenceTimeSkew(enabled)
+	return nil
+}
+
+// HoldPosition holds the vessel in place while sentences keep flowing with a
+// live time, reporting either zero or the commanded speed over ground
+func (a *App) HoldPosition(hold bool, reportCommandedSpeed bool) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
 	}
 
+	a.simulator.SetHoldReportsSpeed(reportCommandedSpeed)
+	a.simulator.HoldPosition(hold)
 	return nil
 }
 
+// SetRouteRange follows only waypoints start through end of the route,
+// starting the vessel at waypoint start
+func (a *App) SetRouteRange(start, end int) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	if a.mode != "rtz" {
+		return fmt.Errorf("waypoint navigation only available in RTZ mode")
+	}
+
+	return a.simulator.SetActiveRange(start, end)
+}
+
+// OffsetRoute shifts the route and the vessel by dLat and dLon degrees to
+// reuse a route in another area
+func (a *App) OffsetRoute(dLat, dLon float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	if a.mode != "rtz" {
+		return fmt.Errorf("waypoint navigation only available in RTZ mode")
+	}
+
+	return a.simulator.OffsetRoute(dLat, dLon)
+}
+
+// DensifyRoute inserts great-circle waypoints so no leg is longer than
+// maxLegNM, making long ocean legs follow the proper curve
+func (a *App) DensifyRoute(maxLegNM float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	if a.mode != "rtz" {
+		return fmt.Errorf("waypoint navigation only available in RTZ mode")
+	}
+
+	return a.simulator.DensifyRoute(maxLegNM)
+}
+
+// GetOriginalRoute returns the route as loaded, before any densifying
+func (a *App) GetOriginalRoute() (*RTZRoute, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return nil, nmea.ErrNotRunning
+	}
+
+	route := a.simulator.GetOriginalRoute()
+	if route == nil {
+		return nil, nmea.ErrNoRoute
+	}
+
+	return routeToJSON(route), nil
+}
+
+// routeToJSON converts a simulator route for the frontend
+func routeToJSON(route *nmea.RTZRoute) *RTZRoute {
+	converted := &RTZRoute{
+		Name:       route.Name,
+		VesselName: route.VesselName,
+		VesselIMO:  route.VesselIMO,
+		Version:    route.Version,
+		Waypoints:  make([]Waypoint, len(route.Waypoints)),
+	}
+	for i, wp := range route.Waypoints {
+		converted.Waypoints[i] = Waypoint{
+			ID:           wp.ID,
+			Name:         wp.Name,
+			Latitude:     wp.Latitude,
+			Longitude:    wp.Longitude,
+			SteeringMode: wp.SteeringMode,
+			Radius:       wp.Radius,
+		}
+	}
+	return converted
+}
+
+// SetWaypointJumpMode sets whether manual waypoint changes teleport the vessel
+// ("teleport") or have it sail to the new target ("navigate")
+func (a *App) SetWaypointJumpMode(mode string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	if a.mode != "rtz" {
+		return fmt.Errorf("waypoint navigation only available in RTZ mode")
+	}
+
+	return a.simulator.SetWaypointJumpMode(mode)
+}
+
+// SetArrivalRadius sets how close the vessel must come to a waypoint for it to
+// count as reached, with unit "nm" or "m"
+func (a *App) SetArrivalRadius(value float64, unit string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetArrivalRadius(value, unit)
+}
+
+// SetArrivalHysteresis sets how far beyond the arrival radius the vessel must
+// move from a reached waypoint before it can be reached again, with unit "nm"
+// or "m"
+func (a *App) SetArrivalHysteresis(value float64, unit string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetArrivalHysteresis(value, unit)
+}
+
+// SetEndOfRouteBehavior sets the end-of-route behavior: "stop", "continue" or "loop"
+func (a *App) SetEndOfRouteBehavior(mode string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetEndOfRouteBehavior(mode)
+}
+
+// SetRouteEndExtension makes the vessel continue past the final waypoint for
+// continueNM nautical miles before stopping, or indefinitely when 0
+func (a *App) SetRouteEndExtension(continueNM float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.isRunning || a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	return a.simulator.SetRouteEndExtension(continueNM)
+}
+
+// GetWaypointBearingDistance returns the bearing and distance between two
+// waypoints of the loaded route, for inspecting leg geometry
+func (a *App) GetWaypointBearingDistance(from, to int) (map[string]interface{}, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return nil, nmea.ErrNotRunning
+	}
+
+	bearing, distance, err := a.simulator.WaypointBearingDistance(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"from":     from,
+		"to":       to,
+		"bearing":  bearing,
+		"distance": distance, // NM
+	}, nil
+}
+
+// GetRouteBounds returns the box enclosing the loaded route, for fitting the
+// map to it. A box crossing the antimeridian has minLon greater than maxLon
+func (a *App) GetRouteBounds() (map[string]interface{}, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return nil, nmea.ErrNotRunning
+	}
+
+	minLat, minLon, maxLat, maxLon, err := a.simulator.GetRouteBounds()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"minLat":              minLat,
+		"minLon":              minLon,
+		"maxLat":              maxLat,
+		"maxLon":              maxLon,
+		"crossesAntimeridian": minLon > maxLon,
+	}, nil
+}
+
 // GetWaypointStatus returns current waypoint status for RTZ mode
 func (a *App) GetWaypointStatus() (map[string]interface{}, error) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
 	if !a.isRunning || a.simulator == nil {
-		return nil, fmt.Errorf("no simulation is running")
+		return nil, nmea.ErrNotRunning
 	}
 
 	if a.mode != "rtz" {
 		return nil, fmt.Errorf("waypoint status only available in RTZ mode")
 	}
 
-	info := a.simulator.GetWaypointInfo()
+	return waypointStatusMap(a.simulator.GetWaypointInfo()), nil
+}
 
+// waypointStatusMap converts waypoint info to the map sent to the frontend
+func waypointStatusMap(info nmea.WaypointInfo) map[string]interface{} {
 	result := map[string]interface{}{
-		"currentWaypoint": info.CurrentWaypoint,
-		"totalWaypoints":  info.TotalWaypoints,
-		"autoNavigate":    info.AutoNavigate,
-		"distanceToTarget": info.DistanceToTarget,
+		"currentWaypoint":     info.CurrentWaypoint,
+		"totalWaypoints":      info.TotalWaypoints,
+		"autoNavigate":        info.AutoNavigate,
+		"distanceToTarget":    info.DistanceToTarget,
+		"remainingDistance":   info.RemainingDistance,
+		"totalDistance":       info.TotalDistance,
+		"progressPercent":     info.ProgressPercent,
+		"stalled":             info.Stalled,
+		"arrivalRadius":       info.ArrivalRadius,
+		"arrivalRadiusMeters": info.ArrivalRadius * 1852,
+		"completed":           info.Completed,
+		"dwellRemaining":      info.DwellRemaining,
+	}
+
+	if info.Warning != "" {
+		result["warning"] = info.Warning
 	}
 
 	if info.TargetWaypoint != nil {
 		result["targetWaypoint"] = map[string]interface{}{
 			"id":        info.TargetWaypoint.ID,
+			"name":      info.TargetWaypoint.Identifier(),
 			"latitude":  info.TargetWaypoint.Latitude,
 			"longitude": info.TargetWaypoint.Longitude,
 		}
 	}
 
-	return result, nil
+	if info.ReachedWaypoint != nil {
+		result["reachedWaypoint"] = map[string]interface{}{
+			"id":        info.ReachedWaypoint.ID,
+			"name":      info.ReachedWaypoint.Identifier(),
+			"latitude":  info.ReachedWaypoint.Latitude,
+			"longitude": info.ReachedWaypoint.Longitude,
+		}
+	}
+
+	return result
 }
 
 // PauseSimulation pauses the current simulation
@@ -545,7 +1849,7 @@ func (a *App) PauseSimulation() error {
 	defer a.mu.Unlock()
 
 	if !a.isRunning || a.simulator == nil {
-		return fmt.Errorf("no simulation is running")
+		return nmea.ErrNotRunning
 	}
 
 	a.simulator.UpdateSpeed(0)
@@ -558,7 +1862,7 @@ func (a *App) ResumeSimulation(speed float64) error {
 	defer a.mu.Unlock()
 
 	if !a.isRunning || a.simulator == nil {
-		return fmt.Errorf("no simulation is running")
+		return nmea.ErrNotRunning
 	}
 
 	a.simulator.UpdateSpeed(speed)
@@ -0,0 +1,70 @@
+// burst.go - Stress testing consumers by transmitting as fast as possible
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"route-sim/nmea"
+)
+
+// RunBurstTest transmits sentences as fast as possible on the given port for
+// durationSeconds and reports the throughput achieved. The sentences describe
+// the current vessel state, or the default state if no simulation has started
+func (a *App) RunBurstTest(durationSeconds int, port int) (nmea.BurstResult, error) {
+	if port <= 0 || port > 65535 {
+		return nmea.BurstResult{}, fmt.Errorf("invalid port %d", port)
+	}
+
+	a.mu.Lock()
+	if a.burstCancel != nil {
+		a.mu.Unlock()
+		return nmea.BurstResult{}, fmt.Errorf("a burst test is already running")
+	}
+
+	// The burst goes to the given port over UDP, not the default vessel's
+	// Unix socket
+	simConfig := a.simulatorConfig(-3.0)
+	simConfig.Port = port
+	simConfig.SocketPath = ""
+
+	simulator, err := nmea.NewSimulator(simConfig)
+	if err != nil {
+		a.mu.Unlock()
+		return nmea.BurstResult{}, fmt.Errorf("failed to create simulator: %w", err)
+	}
+	defer simulator.Close()
+
+	if a.simulator != nil {
+		current := a.simulator.GetCurrentState()
+		simulator.SetPosition(current.Position.Latitude, current.Position.Longitude, current.Speed, current.Course)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.burstCancel = cancel
+	a.mu.Unlock()
+
+	defer func() {
+		a.mu.Lock()
+		a.burstCancel = nil
+		a.mu.Unlock()
+		cancel()
+	}()
+
+	// The app lock is not held while the burst runs, so the UI stays responsive
+	return simulator.RunBurst(ctx, time.Duration(durationSeconds)*time.Second)
+}
+
+// StopBurstTest ends a running burst test early
+func (a *App) StopBurstTest() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.burstCancel == nil {
+		return fmt.Errorf("no burst test is running")
+	}
+
+	a.burstCancel()
+	return nil
+}
@@ -0,0 +1,310 @@
+// fleet.go - Multiple simultaneous vessels, each transmitting on its own port
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"route-sim/nmea"
+)
+
+// DefaultVesselName refers to the vessel driven by the single-vessel methods
+const DefaultVesselName = "default"
+
+// defaultPort is the port used by the default vessel
+const defaultPort = 10110
+
+// VesselConfig configures a fleet vessel
+type VesselConfig struct {
+	Port      int     `json:"port"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Speed     float64 `json:"speed"`
+	Course    float64 `json:"course"`
+}
+
+// VesselStatus represents the state of a fleet vessel for the frontend
+type VesselStatus struct {
+	Name      string   `json:"name"`
+	Port      int      `json:"port"`
+	IsRunning bool     `json:"isRunning"`
+	Position  Position `json:"position"`
+	Speed     float64  `json:"speed"`
+	Course    float64  `json:"course"`
+}
+
+// vessel is a named simulator in the fleet
+type vessel struct {
+	simulator *nmea.Simulator
+	port      int
+	isRunning bool
+}
+
+// CreateVessel creates a named vessel transmitting on its own port
+func (a *App) CreateVessel(name string, config VesselConfig) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if name == "" || name == DefaultVesselName {
+		return fmt.Errorf("invalid vessel name %q", name)
+	}
+
+	if _, exists := a.vessels[name]; exists {
+		return fmt.Errorf("vessel %q already exists", name)
+	}
+
+	if config.Port <= 0 || config.Port > 65535 {
+		return fmt.Errorf("invalid port %d", config.Port)
+	}
+
+	if config.Port == defaultPort {
+		return fmt.Errorf("port %d is used by the default vessel", config.Port)
+	}
+
+	for otherName, other := range a.vessels {
+		if other.port == config.Port {
+			return fmt.Errorf("port %d is already used by vessel %q", config.Port, otherName)
+		}
+	}
+
+	// Each vessel has its own port, so it sends over UDP even when the
+	// default vessel uses a Unix socket
+	simConfig := a.simulatorConfig(-5.0)
+	simConfig.Port = config.Port
+	simConfig.SocketPath = ""
+
+	simulator, err := nmea.NewSimulator(simConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create simulator: %w", err)
+	}
+
+	simulator.SetPosition(config.Latitude, config.Longitude, config.Speed, config.Course)
+
+	a.vessels[name] = &vessel{
+		simulator: simulator,
+		port:      config.Port,
+	}
+	return nil
+}
+
+// StartVessel starts transmission for a named vessel
+func (a *App) StartVessel(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	v, ok := a.vessels[name]
+	if !ok {
+		return fmt.Errorf("vessel %q not found", name)
+	}
+
+	if v.isRunning {
+		return fmt.Errorf("vessel %q is already running", name)
+	}
+
+	if err := v.simulator.Start(); err != nil {
+		return fmt.Errorf("failed to start vessel %q: %w", name, err)
+	}
+
+	v.isRunning = true
+	return nil
+}
+
+// StopVessel stops transmission for a named vessel
+func (a *App) StopVessel(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	v, ok := a.vessels[name]
+	if !ok {
+		return fmt.Errorf("vessel %q not found", name)
+	}
+
+	if !v.isRunning {
+		return fmt.Errorf("vessel %q is not running", name)
+	}
+
+	v.simulator.Stop()
+	v.isRunning = false
+	return nil
+}
+
+// RemoveVessel stops and removes a named vessel, releasing its port
+func (a *App) RemoveVessel(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	v, ok := a.vessels[name]
+	if !ok {
+		return fmt.Errorf("vessel %q not found", name)
+	}
+
+	v.simulator.Close()
+	delete(a.vessels, name)
+	return nil
+}
+
+// UpdateVesselSpeed updates the speed of a named vessel
+func (a *App) UpdateVesselSpeed(name string, speed float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	v, ok := a.vessels[name]
+	if !ok {
+		return fmt.Errorf("vessel %q not found", name)
+	}
+
+	v.simulator.UpdateSpeed(speed)
+	return nil
+}
+
+// UpdateVesselCourse updates the course of a named vessel
+func (a *App) UpdateVesselCourse(name string, course float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	v, ok := a.vessels[name]
+	if !ok {
+		return fmt.Errorf("vessel %q not found", name)
+	}
+
+	v.simulator.UpdateCourse(course)
+	return nil
+}
+
+// GetFleetStatus returns the status of every vessel, including the default
+// vessel once a simulation has been started
+func (a *App) GetFleetStatus() []VesselStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	fleet := []VesselStatus{}
+	if a.simulator != nil {
+		fleet = append(fleet, vesselStatus(DefaultVesselName, defaultPort, a.isRunning, a.simulator))
+	}
+
+	names := make([]string, 0, len(a.vessels))
+	for name := range a.vessels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v := a.vessels[name]
+		fleet = append(fleet, vesselStatus(name, v.port, v.isRunning, v.simulator))
+	}
+
+	return fleet
+}
+
+// vesselStatus builds the frontend status of a vessel
+func vesselStatus(name string, port int, isRunning bool, simulator *nmea.Simulator) VesselStatus {
+	state := simulator.GetCurrentState()
+	return VesselStatus{
+		Name:      name,
+		Port:      port,
+		IsRunning: isRunning,
+		Position: Position{
+			Latitude:  state.Position.Latitude,
+			Longitude: state.Position.Longitude,
+			Timestamp: state.Position.Timestamp,
+		},
+		Speed:  state.Speed,
+		Course: state.Course,
+	}
+}
+
+// closeFleet releases every fleet vessel
+func (a *App) closeFleet() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for name, v := range a.vessels {
+		v.simulator.Close()
+		delete(a.vessels, name)
+	}
+}
+
+// vesselSimulator returns the simulator of a named vessel, where
+// DefaultVesselName refers to the single-vessel simulation
+func (a *App) vesselSimulator(name string) (*nmea.Simulator, error) {
+	if name == DefaultVesselName {
+		if a.simulator == nil {
+			return nil, nmea.ErrNotRunning
+		}
+		return a.simulator, nil
+	}
+
+	v, ok := a.vessels[name]
+	if !ok {
+		return nil, fmt.Errorf("vessel %q not found", name)
+	}
+	return v.simulator, nil
+}
+
+// SetupCPAScenario places vessel B on a crossing course from vessel A's
+// starboard side so the two reach the given closest point of approach (NM)
+// after tcpaSeconds. Vessel A keeps its position and course; a stationary
+// vessel A is given a default speed
+func (a *App) SetupCPAScenario(vesselA, vesselB string, cpaNM, tcpaSeconds float64) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if vesselA == vesselB {
+		return fmt.Errorf("CPA scenario needs two different vessels")
+	}
+
+	if cpaNM < 0 {
+		return fmt.Errorf("CPA cannot be negative")
+	}
+
+	if tcpaSeconds <= 0 {
+		return fmt.Errorf("TCPA must be positive")
+	}
+
+	simA, err := a.vesselSimulator(vesselA)
+	if err != nil {
+		return err
+	}
+
+	simB, err := a.vesselSimulator(vesselB)
+	if err != nil {
+		return err
+	}
+
+	const defaultScenarioSpeed = 10.0 // knots
+
+	stateA := simA.GetCurrentState()
+	speed := stateA.Speed
+	if speed <= 0 {
+		speed = defaultScenarioSpeed
+	}
+
+	courseA := stateA.Course
+	courseB := math.Mod(courseA+270, 360)
+
+	// Work in a local flat frame in NM (x east, y north) and knots
+	velocity := func(course float64) (float64, float64) {
+		rad := course * math.Pi / 180
+		return speed * math.Sin(rad), speed * math.Cos(rad)
+	}
+	vax, vay := velocity(courseA)
+	vbx, vby := velocity(courseB)
+	relX, relY := vbx-vax, vby-vay
+	relSpeed := math.Hypot(relX, relY)
+
+	// At TCPA the relative position is perpendicular to the relative motion
+	// with length CPA, so back it off along the relative velocity
+	hours := tcpaSeconds / 3600
+	offsetX := -relY/relSpeed*cpaNM - relX*hours
+	offsetY := relX/relSpeed*cpaNM - relY*hours
+
+	latA := stateA.Position.Latitude
+	lonA := stateA.Position.Longitude
+	latB := latA + offsetY/60
+	lonB := lonA + offsetX/(60*math.Cos(latA*math.Pi/180))
+
+	simA.SetPosition(latA, lonA, speed, courseA)
+	simB.SetPosition(latB, lonB, speed, courseB)
+	return nil
+}
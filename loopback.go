@@ -0,0 +1,112 @@
+// loopback.go - Capturing the feed on this host to confirm packets arrive
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// maxLoopbackPackets bounds how many captured datagrams are kept
+const maxLoopbackPackets = 200
+
+// loopbackCapture receives datagrams sent to the default port on the loopback
+// interface, keeping the most recent ones
+type loopbackCapture struct {
+	conn     net.PacketConn
+	mu       sync.Mutex
+	packets  []string
+	received int
+}
+
+// startLoopbackCapture listens on 127.0.0.1:port and starts capturing
+func startLoopbackCapture(port int) (*loopbackCapture, error) {
+	conn, err := net.ListenPacket("udp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on port %d - is another receiver using it? %w", port, err)
+	}
+
+	c := &loopbackCapture{conn: conn}
+	go c.run()
+	return c, nil
+}
+
+// run reads datagrams until the socket is closed
+func (c *loopbackCapture) run() {
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := c.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		c.mu.Lock()
+		c.received++
+		c.packets = append(c.packets, strings.TrimRight(string(buf[:n]), "\r\n"))
+		if len(c.packets) > maxLoopbackPackets {
+			c.packets = c.packets[len(c.packets)-maxLoopbackPackets:]
+		}
+		c.mu.Unlock()
+	}
+}
+
+// snapshot returns the captured datagrams, oldest first, and the total received
+func (c *loopbackCapture) snapshot() ([]string, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.packets...), c.received
+}
+
+// close stops capturing
+func (c *loopbackCapture) close() {
+	c.conn.Close()
+}
+
+// SetLoopbackCapture starts or stops a built-in receiver on the default port,
+// so the feed sent to 127.0.0.1 can be checked without a separate tool. It
+// takes the port, so no other receiver on this host can use it meanwhile
+func (a *App) SetLoopbackCapture(enabled bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !enabled {
+		if a.capture != nil {
+			a.capture.close()
+			a.capture = nil
+		}
+		return nil
+	}
+
+	if a.capture != nil {
+		return nil
+	}
+
+	capture, err := startLoopbackCapture(defaultPort)
+	if err != nil {
+		return err
+	}
+	a.capture = capture
+	return nil
+}
+
+// GetLoopbackCapture returns the most recent datagrams received by the
+// loopback capture and how many have been received in total
+func (a *App) GetLoopbackCapture() (map[string]interface{}, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.capture == nil {
+		return nil, fmt.Errorf("loopback capture is not enabled")
+	}
+
+	packets, received := a.capture.snapshot()
+	return map[string]interface{}{
+		"packets":  packets,
+		"received": received,
+	}, nil
+}
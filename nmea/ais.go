@@ -0,0 +1,233 @@
+// ais.go - Simulated AIS base stations and aids to navigation
+package nmea
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// aisReportInterval is how often fixed AIS stations report, the standard
+// rate for a base station
+const aisReportInterval = 10 * time.Second
+
+// maxAtoNName is the length of the name field in a Type 21 report
+const maxAtoNName = 20
+
+// aisStation is a fixed AIS station reported alongside the vessel
+type aisStation struct {
+	mmsi            uint32
+	latitude        float64
+	longitude       float64
+	aidToNavigation bool   // Type 21 aid to navigation rather than a Type 4 base station
+	name            string // aid to navigation name
+}
+
+// parseMMSI validates a nine-digit MMSI
+func parseMMSI(mmsi string) (uint32, error) {
+	mmsi = strings.TrimSpace(mmsi)
+	if len(mmsi) != 9 {
+		return 0, fmt.Errorf("MMSI must be 9 digits, got %q", mmsi)
+	}
+	value, err := strconv.ParseUint(mmsi, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("MMSI must be 9 digits, got %q", mmsi)
+	}
+	return uint32(value), nil
+}
+
+// validateStationPosition checks a fixed station's position is on the globe
+func validateStationPosition(lat, lon float64) error {
+	if lat < -90 || lat > 90 {
+		return fmt.Errorf("latitude %.6f out of range", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return fmt.Errorf("longitude %.6f out of range", lon)
+	}
+	return nil
+}
+
+// AddAISBaseStation adds a base station at a fixed position, reported every
+// ten seconds as an AIS Type 4 message in a !AIVDM sentence
+func (s *Simulator) AddAISBaseStation(mmsi string, lat, lon float64) error {
+	id, err := parseMMSI(mmsi)
+	if err != nil {
+		return err
+	}
+	if err := validateStationPosition(lat, lon); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.aisStations = append(s.aisStations, aisStation{mmsi: id, latitude: lat, longitude: lon})
+	return nil
+}
+
+// AddAISAidToNavigation adds an aid to navigation at a fixed position,
+// reported every ten seconds as an AIS Type 21 message in a !AIVDM sentence.
+// Names longer than the 20 characters AIS allows are truncated
+func (s *Simulator) AddAISAidToNavigation(mmsi, name string, lat, lon float64) error {
+	id, err := parseMMSI(mmsi)
+	if err != nil {
+		return err
+	}
+	if err := validateStationPosition(lat, lon); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.aisStations = append(s.aisStations, aisStation{
+		mmsi:            id,
+		latitude:        lat,
+		longitude:       lon,
+		aidToNavigation: true,
+		name:            truncate(strings.ToUpper(name), maxAtoNName),
+	})
+	return nil
+}
+
+// ClearAISStations removes all base stations and aids to navigation
+func (s *Simulator) ClearAISStations() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.aisStations = nil
+}
+
+// nextAISSentences returns the station reports due this cycle. The caller
+// must hold the write lock
+func (s *Simulator) nextAISSentences(timestamp time.Time) []string {
+	if len(s.aisStations) == 0 || time.Since(s.lastAISReport) < aisReportInterval {
+		return nil
+	}
+	s.lastAISReport = time.Now()
+
+	sentences := make([]string, len(s.aisStations))
+	for i, station := range s.aisStations {
+		if station.aidToNavigation {
+			sentences[i] = s.generateVDM(encodeAtoNReport(station, timestamp))
+		} else {
+			sentences[i] = s.generateVDM(encodeBaseStationReport(station, timestamp))
+		}
+	}
+	return sentences
+}
+
+// encodeBaseStationReport builds the bits of an AIS Type 4 base station report
+func encodeBaseStationReport(station aisStation, timestamp time.Time) *aisPayload {
+	utc := timestamp.UTC()
+
+	p := &aisPayload{}
+	p.add(4, 6)                     // message type
+	p.add(0, 2)                     // repeat indicator
+	p.add(uint64(station.mmsi), 30) // MMSI
+	p.add(uint64(utc.Year()), 14)   // year
+	p.add(uint64(utc.Month()), 4)   // month
+	p.add(uint64(utc.Day()), 5)     // day
+	p.add(uint64(utc.Hour()), 5)    // hour
+	p.add(uint64(utc.Minute()), 6)  // minute
+	p.add(uint64(utc.Second()), 6)  // second
+	p.add(1, 1)                     // position accuracy: high
+	p.addSigned(aisAngle(station.longitude), 28)
+	p.addSigned(aisAngle(station.latitude), 27)
+	p.add(7, 4)  // EPFD type: surveyed
+	p.add(0, 10) // spare
+	p.add(0, 1)  // RAIM
+	p.add(0, 19) // radio status
+	return p
+}
+
+// encodeAtoNReport builds the bits of an AIS Type 21 aid to navigation report
+func encodeAtoNReport(station aisStation, timestamp time.Time) *aisPayload {
+	p := &aisPayload{}
+	p.add(21, 6)                    // message type
+	p.add(0, 2)                     // repeat indicator
+	p.add(uint64(station.mmsi), 30) // MMSI
+	p.add(1, 5)                     // aid type: reference point
+	p.addText(station.name, maxAtoNName)
+	p.add(1, 1) // position accuracy: high
+	p.addSigned(aisAngle(station.longitude), 28)
+	p.addSigned(aisAngle(station.latitude), 27)
+	p.add(0, 30) // dimensions: bow, stern, port, starboard
+	p.add(7, 4)  // EPFD type: surveyed
+	p.add(uint64(timestamp.UTC().Second()), 6)
+	p.add(0, 1) // off position
+	p.add(0, 8) // regional reserved
+	p.add(0, 1) // RAIM
+	p.add(0, 1) // virtual aid: no, a real station
+	p.add(0, 1) // assigned mode
+	p.add(0, 1) // spare
+	return p
+}
+
+// aisAngle converts degrees to the 1/10000 minute units AIS positions use
+func aisAngle(degrees float64) int64 {
+	return int64(math.Round(degrees * 600000))
+}
+
+// generateVDM wraps an AIS payload in a single-part !AIVDM sentence on
+// channel A
+func (s *Simulator) generateVDM(p *aisPayload) string {
+	payload, fill := p.armor()
+	return "!" + strings.TrimPrefix(s.addChecksum(fmt.Sprintf("AIVDM,1,1,,A,%s,%d", payload, fill)), "$")
+}
+
+// aisPayload accumulates the bits of an AIS message, most significant first
+type aisPayload struct {
+	bits []byte
+}
+
+// add appends the low width bits of value
+func (p *aisPayload) add(value uint64, width int) {
+	for i := width - 1; i >= 0; i-- {
+		p.bits = append(p.bits, byte(value>>uint(i)&1))
+	}
+}
+
+// addSigned appends value as a two's complement field of width bits
+func (p *aisPayload) addSigned(value int64, width int) {
+	p.add(uint64(value)&(1<<uint(width)-1), width)
+}
+
+// addText appends text in the AIS six-bit character set, padded with @ to
+// length characters. Characters outside the set are sent as spaces
+func (p *aisPayload) addText(text string, length int) {
+	for i := 0; i < length; i++ {
+		c := byte('@')
+		if i < len(text) {
+			c = text[i]
+		}
+		switch {
+		case c >= '@' && c <= '_':
+			p.add(uint64(c-'@'), 6)
+		case c >= ' ' && c <= '?':
+			p.add(uint64(c), 6)
+		default:
+			p.add(uint64(' '), 6)
+		}
+	}
+}
+
+// armor returns the payload as six-bit ASCII, with the number of fill bits
+// added to complete the last character
+func (p *aisPayload) armor() (string, int) {
+	fill := (6 - len(p.bits)%6) % 6
+	bits := append(p.bits[:len(p.bits):len(p.bits)], make([]byte, fill)...)
+
+	var payload strings.Builder
+	for i := 0; i < len(bits); i += 6 {
+		value := byte(0)
+		for _, bit := range bits[i : i+6] {
+			value = value<<1 | bit
+		}
+		if value < 40 {
+			payload.WriteByte(value + 48)
+		} else {
+			payload.WriteByte(value + 56)
+		}
+	}
+	return payload.String(), fill
+}
@@ -0,0 +1,231 @@
+package nmea
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// aisTime is the fixed time AIS reports are encoded at
+var aisTime = time.Date(2026, time.October, 16, 12, 34, 56, 0, time.UTC)
+
+// dearmor decodes a six-bit ASCII payload back to bits, dropping the fill
+func dearmor(t *testing.T, payload string, fill int) []byte {
+	t.Helper()
+
+	var bits []byte
+	for i := 0; i < len(payload); i++ {
+		value := payload[i] - 48
+		if value > 40 {
+			value -= 8
+		}
+		if value > 63 {
+			t.Fatalf("payload character %q out of range", payload[i])
+		}
+		for b := 5; b >= 0; b-- {
+			bits = append(bits, value>>uint(b)&1)
+		}
+	}
+	return bits[:len(bits)-fill]
+}
+
+// aisField reads an unsigned field of width bits starting at offset
+func aisField(bits []byte, offset, width int) uint64 {
+	var value uint64
+	for _, bit := range bits[offset : offset+width] {
+		value = value<<1 | uint64(bit)
+	}
+	return value
+}
+
+// aisSignedField reads a two's complement field of width bits starting at
+// offset
+func aisSignedField(bits []byte, offset, width int) int64 {
+	value := int64(aisField(bits, offset, width))
+	if bits[offset] == 1 {
+		value -= 1 << uint(width)
+	}
+	return value
+}
+
+// vdmPayload checks a single-part !AIVDM sentence and returns its decoded bits
+func vdmPayload(t *testing.T, sentence string) []byte {
+	t.Helper()
+
+	if err := checkSentence(sentence); err != nil {
+		t.Fatalf("%s: %v", sentence, err)
+	}
+	fields := sentenceFields(sentence)
+	if len(fields) != 7 || fields[0] != "!AIVDM" || fields[1] != "1" || fields[2] != "1" || fields[4] != "A" {
+		t.Fatalf("not a single-part !AIVDM sentence on channel A: %s", sentence)
+	}
+	fill, err := strconv.Atoi(fields[6])
+	if err != nil || fill < 0 || fill > 5 {
+		t.Fatalf("fill bits %q out of range: %s", fields[6], sentence)
+	}
+	return dearmor(t, fields[5], fill)
+}
+
+func TestAISPayloadFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(p *aisPayload)
+		want  string
+	}{
+		{"unsigned", func(p *aisPayload) { p.add(5, 4) }, "0101"},
+		{"unsigned truncated to width", func(p *aisPayload) { p.add(0xff, 3) }, "111"},
+		{"signed positive", func(p *aisPayload) { p.addSigned(3, 4) }, "0011"},
+		{"signed negative", func(p *aisPayload) { p.addSigned(-1, 4) }, "1111"},
+		{"signed minimum", func(p *aisPayload) { p.addSigned(-8, 4) }, "1000"},
+		{"text", func(p *aisPayload) { p.addText("A1", 2) }, "000001110001"},
+		{"text padded", func(p *aisPayload) { p.addText("A", 2) }, "000001000000"},
+		{"text outside the set", func(p *aisPayload) { p.addText("a", 1) }, "100000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &aisPayload{}
+			tt.build(p)
+
+			var got strings.Builder
+			for _, bit := range p.bits {
+				got.WriteByte('0' + bit)
+			}
+			if got.String() != tt.want {
+				t.Errorf("bits %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAISArmor(t *testing.T) {
+	tests := []struct {
+		name     string
+		bits     string
+		want     string
+		wantFill int
+	}{
+		{"zero", "000000", "0", 0},
+		{"below the gap", "100111", "W", 0},
+		{"above the gap", "101000", "`", 0},
+		{"highest", "111111", "w", 0},
+		{"two characters", "000001000010", "12", 0},
+		{"fill", "1", "P", 5},
+		{"fill to a second character", "0000001", "0P", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &aisPayload{}
+			for _, c := range tt.bits {
+				p.add(uint64(c-'0'), 1)
+			}
+
+			payload, fill := p.armor()
+			if payload != tt.want || fill != tt.wantFill {
+				t.Errorf("armor() = %q, %d; want %q, %d", payload, fill, tt.want, tt.wantFill)
+			}
+			if len(p.bits) != len(tt.bits) {
+				t.Errorf("armor() changed the payload to %d bits", len(p.bits))
+			}
+		})
+	}
+}
+
+func TestAISBaseStationReport(t *testing.T) {
+	s := newTestSimulator(t)
+	station := aisStation{mmsi: 2320001, latitude: 51.5, longitude: -0.12}
+
+	sentence := s.generateVDM(encodeBaseStationReport(station, aisTime))
+	if want := "!AIVDM,1,1,,A,402=VPAvb`<RpwwLn0MMw8700000,0*5A"; sentence != want {
+		t.Errorf("sentence %s, want %s", sentence, want)
+	}
+
+	bits := vdmPayload(t, sentence)
+	if len(bits) != 168 {
+		t.Fatalf("%d bits, want 168", len(bits))
+	}
+	fields := []struct {
+		name          string
+		offset, width int
+		want          uint64
+	}{
+		{"message type", 0, 6, 4},
+		{"MMSI", 8, 30, 2320001},
+		{"year", 38, 14, 2026},
+		{"month", 52, 4, 10},
+		{"day", 56, 5, 16},
+		{"hour", 61, 5, 12},
+		{"minute", 66, 6, 34},
+		{"second", 72, 6, 56},
+		{"EPFD type", 134, 4, 7},
+	}
+	for _, f := range fields {
+		if got := aisField(bits, f.offset, f.width); got != f.want {
+			t.Errorf("%s = %d, want %d", f.name, got, f.want)
+		}
+	}
+	if lon := aisSignedField(bits, 79, 28); lon != aisAngle(-0.12) {
+		t.Errorf("longitude %d, want %d", lon, aisAngle(-0.12))
+	}
+	if lat := aisSignedField(bits, 107, 27); lat != aisAngle(51.5) {
+		t.Errorf("latitude %d, want %d", lat, aisAngle(51.5))
+	}
+}
+
+func TestAISAidToNavigationReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		station  aisStation
+		wantName string
+	}{
+		{"named", aisStation{mmsi: 992351001, latitude: 50.7, longitude: -1.1, aidToNavigation: true, name: "NAB TOWER"}, "NAB TOWER"},
+		{"full length", aisStation{mmsi: 992351002, latitude: -33.85, longitude: 151.2, aidToNavigation: true, name: "SYDNEY HEADS LIGHT 1"}, "SYDNEY HEADS LIGHT 1"},
+		{"unnamed", aisStation{mmsi: 992351003, latitude: 0, longitude: 0, aidToNavigation: true}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+
+			sentence := s.generateVDM(encodeAtoNReport(tt.station, aisTime))
+			if fill := sentenceFields(sentence)[6]; fill != "4" {
+				t.Errorf("fill bits %s, want 4: %s", fill, sentence)
+			}
+
+			bits := vdmPayload(t, sentence)
+			if len(bits) != 272 {
+				t.Fatalf("%d bits, want 272", len(bits))
+			}
+			if msgType := aisField(bits, 0, 6); msgType != 21 {
+				t.Errorf("message type %d, want 21", msgType)
+			}
+			if mmsi := aisField(bits, 8, 30); mmsi != uint64(tt.station.mmsi) {
+				t.Errorf("MMSI %d, want %d", mmsi, tt.station.mmsi)
+			}
+
+			var name strings.Builder
+			for i := 0; i < maxAtoNName; i++ {
+				c := byte(aisField(bits, 43+i*6, 6))
+				if c < 32 {
+					c += '@'
+				}
+				name.WriteByte(c)
+			}
+			if got := strings.TrimRight(name.String(), "@"); got != tt.wantName {
+				t.Errorf("name %q, want %q", got, tt.wantName)
+			}
+
+			if lon := aisSignedField(bits, 164, 28); lon != aisAngle(tt.station.longitude) {
+				t.Errorf("longitude %d, want %d", lon, aisAngle(tt.station.longitude))
+			}
+			if lat := aisSignedField(bits, 192, 27); lat != aisAngle(tt.station.latitude) {
+				t.Errorf("latitude %d, want %d", lat, aisAngle(tt.station.latitude))
+			}
+			if second := aisField(bits, 253, 6); second != 56 {
+				t.Errorf("second %d, want 56", second)
+			}
+		})
+	}
+}
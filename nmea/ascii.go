@@ -0,0 +1,60 @@
+package nmea
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sentenceReserved are characters that may not appear inside a sentence body:
+// they delimit sentences, checksums and lines
+const sentenceReserved = "$!*\r\n"
+
+// fieldReserved are characters that may not appear inside a single field,
+// adding the field separator and the remaining NMEA reserved characters
+const fieldReserved = sentenceReserved + ",\\^~"
+
+// validSentenceChar reports whether r may appear in a sentence body: printable
+// 7-bit ASCII other than the sentence delimiters
+func validSentenceChar(r rune) bool {
+	return r >= 0x20 && r <= 0x7e && !strings.ContainsRune(sentenceReserved, r)
+}
+
+// nmeaField strips characters that are reserved in NMEA sentences, or not
+// printable ASCII, from free text placed in a field
+func nmeaField(text string) string {
+	return strings.Map(func(r rune) rune {
+		if !validSentenceChar(r) || strings.ContainsRune(fieldReserved, r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// sanitizeSentenceBody checks a user-supplied sentence body for characters
+// that would corrupt the feed. In strict mode they are rejected; otherwise
+// they are stripped
+func sanitizeSentenceBody(body string, strict bool) (string, error) {
+	for i, r := range body {
+		if validSentenceChar(r) {
+			continue
+		}
+		if strict {
+			return "", fmt.Errorf("invalid character %q at position %d: sentences must be printable ASCII without $, !, * or line breaks", r, i)
+		}
+		return strings.Map(func(r rune) rune {
+			if !validSentenceChar(r) {
+				return -1
+			}
+			return r
+		}, body), nil
+	}
+	return body, nil
+}
+
+// SetStrictASCII sets whether user-supplied sentence text containing
+// non-ASCII or reserved characters is rejected (true) or stripped (false)
+func (s *Simulator) SetStrictASCII(strict bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.strictASCII = strict
+}
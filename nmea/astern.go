@@ -0,0 +1,53 @@
+// astern.go - Moving the vessel stern first
+package nmea
+
+import (
+	"fmt"
+	"math"
+)
+
+// SetAstern sets the vessel moving astern at knots, keeping its heading.
+// Course and speed over ground then report the actual direction of travel,
+// opposite the heading, and a positive speed. A speed ahead, from UpdateSpeed,
+// ends going astern
+func (s *Simulator) SetAstern(knots float64) error {
+	if knots < 0 || math.IsNaN(knots) || math.IsInf(knots, 0) {
+		return fmt.Errorf("astern speed must be zero or more knots")
+	}
+	s.UpdateSpeed(-knots)
+	return nil
+}
+
+// setAstern switches between moving ahead and astern, turning the course
+// over the ground round while the heading stays put. The caller must hold the
+// write lock
+func (s *Simulator) setAstern(astern bool) {
+	if astern != s.astern {
+		s.state.Course = s.normalizeCourse(s.state.Course + 180)
+	}
+	s.astern = astern
+}
+
+// GetHeading returns the direction the bow points, opposite the course over
+// the ground while going astern
+func (s *Simulator) GetHeading() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.heading(s.state)
+}
+
+// IsAstern reports whether the vessel is moving stern first
+func (s *Simulator) IsAstern() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.astern
+}
+
+// heading returns the direction the bow points, which is the course over the
+// ground unless the vessel is going astern. The caller must hold the lock
+func (s *Simulator) heading(state NavigationState) float64 {
+	if s.astern {
+		return s.normalizeCourse(state.Course + 180)
+	}
+	return state.Course
+}
@@ -0,0 +1,75 @@
+package nmea
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// MaxBurstDuration is the longest burst test that can be run
+const MaxBurstDuration = 60 * time.Second
+
+// BurstResult reports the throughput achieved by a burst test
+type BurstResult struct {
+	Sentences          int     `json:"sentences"`
+	Bytes              int     `json:"bytes"`
+	WriteErrors        int     `json:"writeErrors"`
+	DurationSeconds    float64 `json:"durationSeconds"`
+	SentencesPerSecond float64 `json:"sentencesPerSecond"`
+}
+
+// RunBurst transmits sentences as fast as possible for the given duration,
+// ignoring the transmit rate, to stress-test a consumer. It returns early with
+// the results so far if ctx is cancelled. The regular transmit loop must not be
+// running
+func (s *Simulator) RunBurst(ctx context.Context, duration time.Duration) (BurstResult, error) {
+	if duration <= 0 || duration > MaxBurstDuration {
+		return BurstResult{}, fmt.Errorf("burst duration must be between 1 and %.0f seconds", MaxBurstDuration.Seconds())
+	}
+
+	s.mu.RLock()
+	running := s.running
+	terminator := s.lineTerminator
+	s.mu.RUnlock()
+
+	if running {
+		return BurstResult{}, fmt.Errorf("stop the simulation before running a burst test")
+	}
+
+	var result BurstResult
+	start := time.Now()
+	deadline := start.Add(duration)
+
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		s.mu.Lock()
+		state := s.state
+		state.Position.Timestamp = s.gpsTime(time.Now().UTC())
+		sentences := s.cycleSentences(state, s.sky)
+		s.mu.Unlock()
+
+		for _, sentence := range sentences {
+			if sentence == "" {
+				continue
+			}
+			data := []byte(sentence + terminator)
+			if err := s.transport.Send(data); err != nil {
+				result.WriteErrors++
+				continue
+			}
+			result.Sentences++
+			result.Bytes += len(data)
+		}
+
+		// Give other goroutines a chance to run between cycles
+		runtime.Gosched()
+	}
+
+	elapsed := time.Since(start)
+	result.DurationSeconds = elapsed.Seconds()
+	if elapsed > 0 {
+		result.SentencesPerSecond = float64(result.Sentences) / elapsed.Seconds()
+	}
+
+	return result, nil
+}
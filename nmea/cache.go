@@ -0,0 +1,49 @@
+package nmea
+
+// fixCacheKey holds everything the GGA and RMC generators read, so cached
+// sentences are reused only while none of it has changed
+type fixCacheKey struct {
+	state             NavigationState
+	positionPrecision int
+	altitudeUnit      string
+	blankCourse       bool
+	nmeaVersion       string
+	fixStatus         fixStatusTable
+	lowercaseChecksum bool
+}
+
+// fixCache holds the last GGA and RMC sentences generated. A stationary
+// vessel's state doesn't change between cycles, so long idle runs reuse them
+// instead of formatting identical sentences every cycle
+type fixCache struct {
+	key   fixCacheKey
+	valid bool
+	gga   string
+	rmc   string
+}
+
+// cachedFixSentences returns the GGA and RMC sentences for state, generating
+// them only when state or an output setting has changed. The caller must hold
+// the write lock
+func (s *Simulator) cachedFixSentences(state NavigationState) (string, string) {
+	key := fixCacheKey{
+		state:             state,
+		positionPrecision: s.positionPrecision,
+		altitudeUnit:      s.altitudeUnit,
+		blankCourse:       s.blankStoppedCourse,
+		nmeaVersion:       s.nmeaVersion,
+		fixStatus:         s.fixStatusMap,
+		lowercaseChecksum: s.lowercaseChecksum,
+	}
+
+	if !s.fixCache.valid || s.fixCache.key != key {
+		s.fixCache = fixCache{
+			key:   key,
+			valid: true,
+			gga:   s.generateGGA(state),
+			rmc:   s.generateRMC(state),
+		}
+	}
+
+	return s.fixCache.gga, s.fixCache.rmc
+}
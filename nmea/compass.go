@@ -0,0 +1,100 @@
+package nmea
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// MaxDeviation is the largest compass deviation accepted in a deviation table, in degrees
+const MaxDeviation = 30.0
+
+// deviationPoint is a compass deviation at a magnetic heading, east positive
+type deviationPoint struct {
+	Heading   float64
+	Deviation float64
+}
+
+// SetDeviationTable sets the compass deviation in degrees (east positive) at
+// magnetic headings in whole degrees. Deviation between entries is
+// interpolated; an empty table means no deviation, which is the default
+func (s *Simulator) SetDeviationTable(table map[int]float64) error {
+	points := make([]deviationPoint, 0, len(table))
+	for heading, deviation := range table {
+		if heading < 0 || heading >= 360 {
+			return fmt.Errorf("deviation table heading %d must be between 0 and 359", heading)
+		}
+		if math.Abs(deviation) > MaxDeviation {
+			return fmt.Errorf("deviation %.1f at heading %d exceeds %.0f degrees", deviation, heading, MaxDeviation)
+		}
+		points = append(points, deviationPoint{Heading: float64(heading), Deviation: deviation})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Heading < points[j].Heading })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deviation = points
+	return nil
+}
+
+// deviationFor returns the deviation at a magnetic heading, interpolating
+// linearly between table entries and wrapping through north
+func (s *Simulator) deviationFor(heading float64) float64 {
+	table := s.deviation
+	switch len(table) {
+	case 0:
+		return 0
+	case 1:
+		return table[0].Deviation
+	}
+
+	// Find the first entry past the heading; the one before it (wrapping) bounds it below
+	next := sort.Search(len(table), func(i int) bool { return table[i].Heading > heading })
+	lower := table[(next+len(table)-1)%len(table)]
+	upper := table[next%len(table)]
+
+	span := upper.Heading - lower.Heading
+	offset := heading - lower.Heading
+	if span <= 0 {
+		span += 360
+	}
+	if offset < 0 {
+		offset += 360
+	}
+
+	return lower.Deviation + (upper.Deviation-lower.Deviation)*offset/span
+}
+
+// GenerateHDG generates an HDG sentence for state at time t
+func (s *Simulator) GenerateHDG(state NavigationState, t time.Time) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state.Position.Timestamp = t
+	return s.generateHDG(state)
+}
+
+// generateHDG generates an HDG (Heading, Deviation and Variation) sentence.
+// The heading is the magnetic compass reading, so true heading is recovered by
+// applying both deviation and variation
+func (s *Simulator) generateHDG(state NavigationState) string {
+	// Heading is taken as the course, or its reciprocal going astern, as for VBW
+	magnetic := s.normalizeCourse(s.heading(state) - state.MagneticVar)
+	deviation := s.deviationFor(magnetic)
+	compass := s.normalizeCourse(magnetic - deviation)
+
+	sentence := fmt.Sprintf("HCHDG,%.1f,%.1f,%s,%.1f,%s",
+		compass,
+		math.Abs(deviation), eastWest(deviation),
+		math.Abs(state.MagneticVar), eastWest(state.MagneticVar))
+
+	return s.addChecksum(sentence)
+}
+
+// eastWest returns the hemisphere letter for an east-positive angle
+func eastWest(angle float64) string {
+	if angle < 0 {
+		return "W"
+	}
+	return "E"
+}
@@ -0,0 +1,117 @@
+// csvroute.go - Loading routes from a simple CSV of waypoints
+package nmea
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseCSVRoute parses a route from CSV lines of id,lat,lon with optional
+// name and turn radius (NM) columns. A leading header row is skipped, as are
+// blank lines and lines starting with #. Errors give the offending line
+func ParseCSVRoute(data []byte) (*RTZRoute, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	route := &RTZRoute{}
+	first := true
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("line %d: %v", parseErr.Line, parseErr.Err)
+			}
+			return nil, fmt.Errorf("failed to read CSV route: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		isHeader := first && isCSVHeader(record)
+		first = false
+		if isHeader {
+			continue
+		}
+
+		wp, err := parseCSVWaypoint(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		route.Waypoints = append(route.Waypoints, wp)
+	}
+
+	if len(route.Waypoints) == 0 {
+		return nil, fmt.Errorf("no waypoints found in CSV route")
+	}
+
+	return route, nil
+}
+
+// isCSVHeader reports whether the first row is column names rather than a
+// waypoint, judged by a latitude column that isn't a number
+func isCSVHeader(record []string) bool {
+	if len(record) < 2 {
+		return false
+	}
+	_, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+	return err != nil
+}
+
+// parseCSVWaypoint converts one id,lat,lon[,name[,radius]] row to a waypoint
+func parseCSVWaypoint(record []string) (Waypoint, error) {
+	if len(record) < 3 || len(record) > 5 {
+		return Waypoint{}, fmt.Errorf("expected id,lat,lon[,name[,radius]], got %d fields", len(record))
+	}
+
+	for i := range record {
+		record[i] = strings.TrimSpace(record[i])
+	}
+
+	lat, err := strconv.ParseFloat(record[1], 64)
+	if err != nil {
+		return Waypoint{}, fmt.Errorf("invalid latitude %q", record[1])
+	}
+	if lat < -90 || lat > 90 {
+		return Waypoint{}, fmt.Errorf("latitude %.6f out of range", lat)
+	}
+
+	lon, err := strconv.ParseFloat(record[2], 64)
+	if err != nil {
+		return Waypoint{}, fmt.Errorf("invalid longitude %q", record[2])
+	}
+	if lon < -180 || lon > 180 {
+		return Waypoint{}, fmt.Errorf("longitude %.6f out of range", lon)
+	}
+
+	wp := Waypoint{
+		ID:           record[0],
+		Latitude:     lat,
+		Longitude:    lon,
+		SteeringMode: SteeringFlyOver,
+	}
+
+	if len(record) > 3 {
+		wp.Name = record[3]
+	}
+
+	if len(record) > 4 && record[4] != "" {
+		radius, err := strconv.ParseFloat(record[4], 64)
+		if err != nil || radius <= 0 {
+			return Waypoint{}, fmt.Errorf("invalid turn radius %q", record[4])
+		}
+		wp.Radius = radius
+		wp.SteeringMode = SteeringFlyBy
+	}
+
+	return wp, nil
+}
@@ -0,0 +1,99 @@
+package nmea
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxDensifiedWaypoints limits how many waypoints densifying a route may produce
+const MaxDensifiedWaypoints = 10000
+
+// DensifyRoute inserts intermediate waypoints along the great circle of every
+// leg longer than maxLegNM, so long ocean legs follow the proper curve on a
+// plotter. The route as loaded remains available from GetOriginalRoute
+func (s *Simulator) DensifyRoute(maxLegNM float64) error {
+	if maxLegNM <= 0 {
+		return fmt.Errorf("maximum leg length must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.route == nil {
+		return ErrNoRoute
+	}
+
+	// Always densify the original, so repeated calls don't compound
+	source := s.route
+	target := s.currentWaypoint
+	if s.originalRoute != nil {
+		source = s.originalRoute
+		if target < len(s.sourceIndex) {
+			target = s.sourceIndex[target]
+		} else {
+			target = len(source.Waypoints)
+		}
+	}
+
+	densified := source.withWaypoints([]Waypoint{source.Waypoints[0]})
+	sourceIndex := []int{0}
+	newTarget := 0
+
+	for i := 1; i < len(source.Waypoints); i++ {
+		from := source.Waypoints[i-1]
+		to := source.Waypoints[i]
+
+		distance := s.calculateDistance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+		segments := int(math.Max(1, math.Ceil(distance/maxLegNM)))
+		if len(densified.Waypoints)+segments > MaxDensifiedWaypoints {
+			return fmt.Errorf("densifying would exceed %d waypoints; use a longer maximum leg", MaxDensifiedWaypoints)
+		}
+
+		// On the vessel's leg, target the first new waypoint still ahead of it
+		if i == target {
+			travelled := s.calculateDistance(from.Latitude, from.Longitude,
+				s.state.Position.Latitude, s.state.Position.Longitude)
+			ahead := int(math.Min(float64(segments), math.Floor(travelled/(distance/float64(segments)))+1))
+			newTarget = len(densified.Waypoints) - 1 + ahead
+		}
+
+		// Intermediate points lie on the great circle leaving from at its initial bearing
+		bearing := s.calculateCourse(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+		for j := 1; j < segments; j++ {
+			lat, lon := s.calculateNewPosition(from.Latitude, from.Longitude, bearing, distance*float64(j)/float64(segments))
+			densified.Waypoints = append(densified.Waypoints, Waypoint{
+				ID:           fmt.Sprintf("%s-%d", from.ID, j),
+				Latitude:     lat,
+				Longitude:    lon,
+				SteeringMode: SteeringFlyOver,
+			})
+			sourceIndex = append(sourceIndex, i)
+		}
+
+		densified.Waypoints = append(densified.Waypoints, to)
+		sourceIndex = append(sourceIndex, i)
+	}
+
+	// Past the end of the route stays past the end
+	if target >= len(source.Waypoints) {
+		newTarget = len(densified.Waypoints)
+	}
+
+	s.originalRoute = source
+	s.sourceIndex = sourceIndex
+	s.route = densified
+	s.currentWaypoint = newTarget
+	s.arrivalLatch = -1
+	return nil
+}
+
+// GetOriginalRoute returns the route as loaded, before any densifying
+func (s *Simulator) GetOriginalRoute() *RTZRoute {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.originalRoute != nil {
+		return s.originalRoute
+	}
+	return s.route
+}
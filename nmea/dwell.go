@@ -0,0 +1,99 @@
+// dwell.go - Holding at waypoints before carrying on along the route
+package nmea
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// dwellTable holds the time to dwell at waypoints of the whole route, by index
+type dwellTable map[int]time.Duration
+
+// SetWaypointDwell sets how long the vessel holds position on reaching
+// waypoint index before carrying on at its previous speed, as for a pilot
+// station or loiter point. Zero removes the dwell. As with SetActiveRange, the
+// index refers to the whole route, and the dwell is kept when the route is
+// restricted to a range or densified; loading a route clears all dwells
+func (s *Simulator) SetWaypointDwell(index int, seconds float64) error {
+	if seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return fmt.Errorf("dwell time must be zero or more seconds")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	whole := s.wholeRoute()
+	if whole == nil {
+		return ErrNoRoute
+	}
+	if index < 0 || index >= len(whole.Waypoints) {
+		return fmt.Errorf("%w: index %d must be between 0 and %d",
+			ErrInvalidWaypoint, index, len(whole.Waypoints)-1)
+	}
+
+	if seconds == 0 {
+		delete(s.dwells, index)
+		return nil
+	}
+	if s.dwells == nil {
+		s.dwells = make(dwellTable)
+	}
+	s.dwells[index] = time.Duration(seconds * float64(time.Second))
+	return nil
+}
+
+// wholeIndex returns the index in the whole route of waypoint index of the
+// route being followed, or -1 for a waypoint inserted by densifying. The
+// caller must hold the lock
+func (s *Simulator) wholeIndex(index int) int {
+	if s.sourceIndex != nil {
+		// Inserted waypoints share the index of the waypoint ending their leg,
+		// which comes after them
+		if index+1 < len(s.sourceIndex) && s.sourceIndex[index+1] == s.sourceIndex[index] {
+			return -1
+		}
+		index = s.sourceIndex[index]
+	}
+	return s.rangeStart + index
+}
+
+// startDwell holds the vessel at waypoint index of the route being followed,
+// which it has just reached, if the waypoint has a dwell time. The caller
+// must hold the write lock
+func (s *Simulator) startDwell(index int) {
+	dwell := s.dwells[s.wholeIndex(index)]
+	if dwell <= 0 {
+		return
+	}
+	if s.dwellLeft <= 0 {
+		s.dwellSpeed = s.state.Speed
+	}
+	s.dwellLeft = dwell
+	s.state.Speed = 0
+}
+
+// endDwell ends any dwell early, restoring the speed held before it. The
+// caller must hold the write lock
+func (s *Simulator) endDwell() {
+	if s.dwellLeft > 0 {
+		s.dwellLeft = 0
+		s.state.Speed = s.dwellSpeed
+	}
+}
+
+// stepDwell counts down a dwell by dt, resuming the previous speed when it
+// runs out. It reports whether the vessel is still dwelling. The caller must
+// hold the write lock
+func (s *Simulator) stepDwell(dt time.Duration) bool {
+	if s.dwellLeft <= 0 {
+		return false
+	}
+	s.dwellLeft -= dt
+	if s.dwellLeft > 0 {
+		return true
+	}
+	s.dwellLeft = 0
+	s.state.Speed = s.dwellSpeed
+	return false
+}
@@ -0,0 +1,156 @@
+package nmea
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaypointDwellHoldsThenResumes(t *testing.T) {
+	s := newTestSimulator(t)
+
+	route := testRoute([2]float64{50, 0}, [2]float64{50.01, 0}, [2]float64{50.1, 0})
+	if err := s.SetRoute(route, 12); err != nil {
+		t.Fatalf("SetRoute: %v", err)
+	}
+	if err := s.SetWaypointDwell(1, 30); err != nil {
+		t.Fatalf("SetWaypointDwell: %v", err)
+	}
+
+	// Sail until the dwell at waypoint 1 begins
+	for i := 0; s.dwellLeft == 0; i++ {
+		if i > 600 {
+			t.Fatal("dwell never started")
+		}
+		s.Step(time.Second)
+	}
+
+	held := s.GetCurrentState().Position
+	for i := 0; i < 25; i++ {
+		s.Step(time.Second)
+		state := s.GetCurrentState()
+		if state.Speed != 0 {
+			t.Fatalf("%ds into the dwell: speed %.1f, want 0", i+1, state.Speed)
+		}
+		if state.Position.Latitude != held.Latitude || state.Position.Longitude != held.Longitude {
+			t.Fatalf("%ds into the dwell: moved from %.6f,%.6f to %.6f,%.6f", i+1,
+				held.Latitude, held.Longitude, state.Position.Latitude, state.Position.Longitude)
+		}
+	}
+
+	s.Step(10 * time.Second)
+	state := s.GetCurrentState()
+	if state.Speed != 12 {
+		t.Errorf("after the dwell: speed %.1f, want 12", state.Speed)
+	}
+	if state.Position.Latitude <= held.Latitude {
+		t.Error("vessel did not move on after the dwell")
+	}
+}
+
+func TestWaypointDwellKeptWhenRouteRebuilt(t *testing.T) {
+	points := [][2]float64{{50, 0}, {50.01, 0}, {50.02, 0}, {50.03, 0}, {50.04, 0}}
+
+	tests := []struct {
+		name        string
+		dwellFirst  bool // set the dwell before rebuilding the route
+		rebuild     func(s *Simulator) error
+		dwellAt     int // index in the whole route
+		wantReached int // index in the whole route of the first waypoint dwelt at
+	}{
+		{"range", true, func(s *Simulator) error { return s.SetActiveRange(1, 3) }, 2, 2},
+		{"range then dwell", false, func(s *Simulator) error { return s.SetActiveRange(1, 3) }, 2, 2},
+		{"densify", true, func(s *Simulator) error { return s.DensifyRoute(0.25) }, 1, 1},
+		{"densify then dwell", false, func(s *Simulator) error { return s.DensifyRoute(0.25) }, 2, 2},
+		{"range and densify", true, func(s *Simulator) error {
+			if err := s.SetActiveRange(1, 4); err != nil {
+				return err
+			}
+			return s.DensifyRoute(0.25)
+		}, 3, 3},
+		{"densify and restore", true, func(s *Simulator) error {
+			if err := s.DensifyRoute(0.25); err != nil {
+				return err
+			}
+			return s.SetActiveRange(0, 4)
+		}, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+			if err := s.SetRoute(testRoute(points...), 12); err != nil {
+				t.Fatalf("SetRoute: %v", err)
+			}
+
+			if tt.dwellFirst {
+				if err := s.SetWaypointDwell(tt.dwellAt, 30); err != nil {
+					t.Fatalf("SetWaypointDwell: %v", err)
+				}
+			}
+			if err := tt.rebuild(s); err != nil {
+				t.Fatalf("rebuilding the route: %v", err)
+			}
+			if !tt.dwellFirst {
+				if err := s.SetWaypointDwell(tt.dwellAt, 30); err != nil {
+					t.Fatalf("SetWaypointDwell: %v", err)
+				}
+			}
+
+			for i := 0; s.dwellLeft == 0; i++ {
+				if i > 3600 || s.routeComplete {
+					t.Fatal("dwell never started")
+				}
+				s.Step(time.Second)
+			}
+
+			want := points[tt.wantReached]
+			pos := s.GetCurrentState().Position
+			if distance := s.calculateDistance(pos.Latitude, pos.Longitude, want[0], want[1]); distance > s.arrivalRadius {
+				t.Errorf("dwelling %.3f NM from waypoint %d, want at it", distance, tt.wantReached)
+			}
+		})
+	}
+}
+
+func TestWaypointJumpEndsDwell(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+		jump func(s *Simulator) error
+	}{
+		{"previous", WaypointJumpTeleport, (*Simulator).GoToPreviousWaypoint},
+		{"previous navigating", WaypointJumpNavigate, (*Simulator).GoToPreviousWaypoint},
+		{"set current", WaypointJumpTeleport, func(s *Simulator) error { return s.SetCurrentWaypoint(1) }},
+		{"set current navigating", WaypointJumpNavigate, func(s *Simulator) error { return s.SetCurrentWaypoint(1) }},
+		{"next", WaypointJumpTeleport, (*Simulator).AdvanceToNextWaypoint},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+
+			route := testRoute([2]float64{50, 0}, [2]float64{50.01, 0}, [2]float64{50.02, 0}, [2]float64{50.03, 0})
+			if err := s.SetRoute(route, 12); err != nil {
+				t.Fatalf("SetRoute: %v", err)
+			}
+			if err := s.SetWaypointJumpMode(tt.mode); err != nil {
+				t.Fatalf("SetWaypointJumpMode: %v", err)
+			}
+
+			// Dwelling at waypoint 1 with waypoint 2 as the next target
+			if err := s.SetWaypointDwell(1, 60); err != nil {
+				t.Fatalf("SetWaypointDwell: %v", err)
+			}
+			s.currentWaypoint = 2
+			s.startDwell(1)
+
+			if err := tt.jump(s); err != nil {
+				t.Fatalf("jump: %v", err)
+			}
+			if s.dwellLeft != 0 || s.GetCurrentState().Speed != 12 {
+				t.Errorf("after jump: dwell %s, speed %.1f; want no dwell and speed 12",
+					s.dwellLeft, s.GetCurrentState().Speed)
+			}
+		})
+	}
+}
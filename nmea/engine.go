@@ -0,0 +1,54 @@
+package nmea
+
+import (
+	"fmt"
+	"math"
+)
+
+// engineModel ties shaft RPM to speed through the water for a fixed-pitch
+// propeller: the ratio of maxSpeed to maxRPM stands in for the pitch
+type engineModel struct {
+	maxRPM   float64
+	maxSpeed float64 // knots through the water at maxRPM
+}
+
+// SetEngineModel enables RPM output, with the shaft turning at maxRPM when the
+// vessel makes maxSpeed knots through the water and proportionally slower
+// below that. A maxRPM of zero disables RPM output
+func (s *Simulator) SetEngineModel(maxRPM, maxSpeed float64) error {
+	if maxRPM < 0 {
+		return fmt.Errorf("maximum RPM cannot be negative")
+	}
+	if maxRPM > 0 && maxSpeed <= 0 {
+		return fmt.Errorf("maximum speed must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if maxRPM == 0 {
+		s.engine = nil
+		return nil
+	}
+	s.engine = &engineModel{maxRPM: maxRPM, maxSpeed: maxSpeed}
+	return nil
+}
+
+// shaftRPM returns the shaft speed needed to make the vessel's speed through
+// the water, so it follows any speed change including the warm-up ramp. The
+// shaft turns backward, giving negative RPM, when going astern
+func (s *Simulator) shaftRPM(state NavigationState) float64 {
+	longitudinal, _ := s.waterVelocity(state)
+	rpm := longitudinal / s.engine.maxSpeed * s.engine.maxRPM
+	if s.astern {
+		return s.clamp(rpm, -s.engine.maxRPM, 0)
+	}
+	return s.clamp(rpm, 0, s.engine.maxRPM)
+}
+
+// generateRPM generates an RPM (Revolutions) sentence for shaft 1. The pitch
+// field is left empty as the propeller is fixed-pitch
+func (s *Simulator) generateRPM(state NavigationState) string {
+	rpm := math.Round(s.shaftRPM(state))
+	return s.addChecksum(fmt.Sprintf("ERRPM,S,1,%.0f,,A", rpm))
+}
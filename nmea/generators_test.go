@@ -0,0 +1,306 @@
+package nmea
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// generatorTime is the fixed time every golden sentence is generated at
+var generatorTime = time.Date(2026, time.March, 14, 9, 26, 53, 590_000_000, time.UTC)
+
+// generatorStates are the states the golden files are generated from
+var generatorStates = []struct {
+	name  string
+	state NavigationState
+}{
+	{"north_east", NavigationState{
+		Position: Position{Latitude: 51.507351, Longitude: 0.127758},
+		Speed:    12.4, Course: 73.2, MagneticVar: 1.5,
+		FixQuality: 1, Satellites: 8, HDOP: 0.9, Altitude: 12.3,
+	}},
+	{"south_west", NavigationState{
+		Position: Position{Latitude: -34.603722, Longitude: -58.381592},
+		Speed:    8.7, Course: 214.9, MagneticVar: -8.2,
+		FixQuality: 2, Satellites: 10, HDOP: 0.7, Altitude: 4.1, DGPSAge: 3.2, DGPSStationID: 17,
+	}},
+	{"zero_speed", NavigationState{
+		Position: Position{Latitude: 53.344104, Longitude: -6.267494},
+		Speed:    0, Course: 0, MagneticVar: -3.0,
+		FixQuality: 1, Satellites: 7, HDOP: 1.2, Altitude: 8.0,
+	}},
+	{"high_latitude", NavigationState{
+		Position: Position{Latitude: 82.501389, Longitude: -62.348056},
+		Speed:    5.2, Course: 341.7, MagneticVar: -30.4,
+		FixQuality: 1, Satellites: 6, HDOP: 1.8, Altitude: 30.0,
+	}},
+	{"no_fix", NavigationState{
+		Position: Position{Latitude: 51.507351, Longitude: 0.127758},
+		Speed:    12.4, Course: 73.2, MagneticVar: 1.5,
+		FixQuality: 0, Satellites: 0, HDOP: 99.9,
+	}},
+}
+
+// generateAll generates every sentence the exported generators produce for
+// state, in a fixed order
+func generateAll(s *Simulator, state NavigationState) []string {
+	sentences := []string{
+		s.GenerateGGA(state, generatorTime),
+		s.GenerateRMC(state, generatorTime),
+		s.GenerateGLL(state, generatorTime),
+		s.GenerateVTG(state, generatorTime),
+		s.GenerateHDG(state, generatorTime),
+		s.GenerateVBW(state, generatorTime),
+		s.GenerateGSA(state, generatorTime),
+	}
+	return append(sentences, s.GenerateGSV(state, generatorTime)...)
+}
+
+func TestGeneratorsGolden(t *testing.T) {
+	for _, tt := range generatorStates {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+			sentences := generateAll(s, tt.state)
+
+			for _, sentence := range sentences {
+				if err := checkSentence(sentence); err != nil {
+					t.Errorf("%s: %v", sentence, err)
+				}
+			}
+
+			got := strings.Join(sentences, "\n") + "\n"
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create it): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("sentences differ from %s\ngot:\n%swant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+// checkSentence verifies a sentence's checksum, in either case, and that it
+// fits the NMEA 0183 length limit once terminated
+func checkSentence(sentence string) error {
+	if len(sentence)+len("\r\n") > maxSentenceLength {
+		return fmt.Errorf("%d characters with CR LF, over the %d limit", len(sentence)+2, maxSentenceLength)
+	}
+	if !strings.HasPrefix(sentence, "$") && !strings.HasPrefix(sentence, "!") {
+		return fmt.Errorf("does not start with $ or !")
+	}
+
+	star := strings.LastIndex(sentence, "*")
+	if star < 0 || len(sentence)-star != 3 {
+		return fmt.Errorf("no two-digit checksum")
+	}
+	sent, err := strconv.ParseUint(sentence[star+1:], 16, 8)
+	if err != nil {
+		return fmt.Errorf("checksum %q is not hex", sentence[star+1:])
+	}
+
+	var sum byte
+	for i := 1; i < star; i++ {
+		sum ^= sentence[i]
+	}
+	if byte(sent) != sum {
+		return fmt.Errorf("checksum %02X, want %02X", sent, sum)
+	}
+	return nil
+}
+
+// sentenceFields returns the comma-separated fields of a sentence, without
+// the checksum
+func sentenceFields(sentence string) []string {
+	if star := strings.LastIndex(sentence, "*"); star >= 0 {
+		sentence = sentence[:star]
+	}
+	return strings.Split(sentence, ",")
+}
+
+func TestModeIndicatorByVersion(t *testing.T) {
+	tests := []struct {
+		version    string
+		quality    int
+		wantFields map[string]int // field count, including the address, by sentence
+		wantMode   string
+	}{
+		{NMEAVersion21, 1, map[string]int{"VTG": 9, "RMC": 12, "GLL": 7}, ""},
+		{NMEAVersion23, 0, map[string]int{"VTG": 10, "RMC": 13, "GLL": 8}, "N"},
+		{NMEAVersion23, 1, map[string]int{"VTG": 10, "RMC": 13, "GLL": 8}, "A"},
+		{NMEAVersion23, 2, map[string]int{"VTG": 10, "RMC": 13, "GLL": 8}, "D"},
+		{NMEAVersion23, 6, map[string]int{"VTG": 10, "RMC": 13, "GLL": 8}, "E"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+"/"+strconv.Itoa(tt.quality), func(t *testing.T) {
+			s := newTestSimulator(t)
+			if err := s.SetNMEAVersion(tt.version); err != nil {
+				t.Fatalf("SetNMEAVersion: %v", err)
+			}
+
+			state := generatorStates[0].state
+			state.FixQuality = tt.quality
+			sentences := map[string]string{
+				"VTG": s.GenerateVTG(state, generatorTime),
+				"RMC": s.GenerateRMC(state, generatorTime),
+				"GLL": s.GenerateGLL(state, generatorTime),
+			}
+
+			for name, sentence := range sentences {
+				fields := sentenceFields(sentence)
+				if len(fields) != tt.wantFields[name] {
+					t.Errorf("%s has %d fields, want %d: %s", name, len(fields), tt.wantFields[name], sentence)
+					continue
+				}
+				if tt.wantMode != "" && fields[len(fields)-1] != tt.wantMode {
+					t.Errorf("%s mode %q, want %q: %s", name, fields[len(fields)-1], tt.wantMode, sentence)
+				}
+			}
+		})
+	}
+}
+
+func TestVTGMagneticCourseMatchesRMCVariation(t *testing.T) {
+	tests := []struct {
+		course, variation float64
+		wantMagnetic      string
+		wantHemisphere    string
+	}{
+		{100, 3.0, "97.0", "E"},
+		{100, -3.0, "103.0", "W"},
+		{100, 0, "100.0", "E"},
+		{1, 3.0, "358.0", "E"},
+		{359, -3.0, "2.0", "W"},
+		{180, -30.4, "210.4", "W"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v at %v", tt.course, tt.variation), func(t *testing.T) {
+			s := newTestSimulator(t)
+
+			state := generatorStates[0].state
+			state.Course = tt.course
+			state.MagneticVar = tt.variation
+
+			vtg := sentenceFields(s.GenerateVTG(state, generatorTime))
+			rmc := sentenceFields(s.GenerateRMC(state, generatorTime))
+
+			if vtg[3] != tt.wantMagnetic || vtg[4] != "M" {
+				t.Errorf("VTG magnetic course %s,%s, want %s,M", vtg[3], vtg[4], tt.wantMagnetic)
+			}
+			if rmc[11] != tt.wantHemisphere {
+				t.Errorf("RMC variation hemisphere %s, want %s", rmc[11], tt.wantHemisphere)
+			}
+
+			// Recover the magnetic course from RMC the way a consumer would:
+			// westerly variation adds to the true course, easterly subtracts
+			variation, _ := strconv.ParseFloat(rmc[10], 64)
+			if rmc[11] == "E" {
+				variation = -variation
+			}
+			trueCourse, _ := strconv.ParseFloat(rmc[8], 64)
+			magnetic := math.Mod(trueCourse+variation+360, 360)
+			if got := strconv.FormatFloat(magnetic, 'f', 1, 64); got != vtg[3] {
+				t.Errorf("magnetic course from RMC %s, VTG reports %s", got, vtg[3])
+			}
+		})
+	}
+}
+
+func TestChecksumCase(t *testing.T) {
+	tests := []struct {
+		name      string
+		lowercase bool
+		digits    string
+	}{
+		{"uppercase", false, "0123456789ABCDEF"},
+		{"lowercase", true, "0123456789abcdef"},
+	}
+
+	upper := generateAll(newTestSimulator(t), generatorStates[0].state)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+			s.SetLowercaseChecksum(tt.lowercase)
+
+			sentences := generateAll(s, generatorStates[0].state)
+			letters := false
+			for i, sentence := range sentences {
+				checksum := sentence[len(sentence)-2:]
+				if strings.Trim(checksum, tt.digits) != "" {
+					t.Errorf("checksum %q not in %s hex: %s", checksum, tt.name, sentence)
+				}
+				letters = letters || strings.ContainsAny(checksum, "abcdefABCDEF")
+
+				// Both forms validate and carry the same value
+				if err := checkSentence(sentence); err != nil {
+					t.Errorf("%s: %v", sentence, err)
+				}
+				if !strings.EqualFold(sentence, upper[i]) {
+					t.Errorf("sentence %s differs from %s beyond case", sentence, upper[i])
+				}
+			}
+			if !letters {
+				t.Error("no checksum with hex letters to check the case of")
+			}
+		})
+	}
+}
+
+func TestHDGDeviationTable(t *testing.T) {
+	table := map[int]float64{0: 1, 90: 3, 180: -1, 270: -3}
+
+	tests := []struct {
+		name      string
+		table     map[int]float64
+		course    float64
+		variation float64
+		want      string // heading, deviation and variation fields
+	}{
+		{"no table", nil, 100, 0, "100.0,0.0,E,0.0,E"},
+		{"no table with variation", nil, 100, -3, "103.0,0.0,E,3.0,W"},
+		{"on an entry", table, 90, 0, "87.0,3.0,E,0.0,E"},
+		{"interpolated", table, 45, 0, "43.0,2.0,E,0.0,E"},
+		{"west", table, 270, 0, "273.0,3.0,W,0.0,E"},
+		{"through north", table, 315, 0, "316.0,1.0,W,0.0,E"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+			if tt.table != nil {
+				if err := s.SetDeviationTable(tt.table); err != nil {
+					t.Fatalf("SetDeviationTable: %v", err)
+				}
+			}
+
+			state := generatorStates[0].state
+			state.Course = tt.course
+			state.MagneticVar = tt.variation
+
+			sentence := s.GenerateHDG(state, generatorTime)
+			if err := checkSentence(sentence); err != nil {
+				t.Errorf("%s: %v", sentence, err)
+			}
+			if got := strings.Join(sentenceFields(sentence)[1:], ","); got != tt.want {
+				t.Errorf("HDG fields %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
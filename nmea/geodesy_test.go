@@ -0,0 +1,125 @@
+package nmea
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHighLatitudePositionsStayValid(t *testing.T) {
+	tests := []struct {
+		name       string
+		lat, lon   float64
+		course     float64
+		distanceNM float64
+	}{
+		{"towards north pole", 89.99, 10, 0, 1},
+		{"from north pole", 90, 0, 135, 1},
+		{"from south pole", -90, 0, 10, 1},
+		{"along parallel at 89N", 89, -170, 90, 30},
+		{"across south pole", -89.995, 45, 180, 2},
+		{"zero distance at pole", 90, 0, 0, 0},
+	}
+
+	s := newTestSimulator(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lat, lon := s.calculateNewPosition(tt.lat, tt.lon, tt.course, tt.distanceNM)
+			if math.IsNaN(lat) || math.IsNaN(lon) || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+				t.Fatalf("new position %v, %v out of range", lat, lon)
+			}
+
+			course := s.calculateCourse(tt.lat, tt.lon, lat, lon)
+			if math.IsNaN(course) || course < 0 || course >= 360 {
+				t.Errorf("course back from new position %v out of range", course)
+			}
+			if distance := s.calculateDistance(tt.lat, tt.lon, lat, lon); math.Abs(distance-tt.distanceNM) > 1e-3 {
+				t.Errorf("moved %.4f NM, want %.4f", distance, tt.distanceNM)
+			}
+		})
+	}
+}
+
+func TestTransitOverNorthPole(t *testing.T) {
+	s := newTestSimulator(t)
+
+	// Heading due north at 89.98N, under four minutes from the pole at 20 knots
+	s.SetPosition(89.98, 30, 20, 0)
+	crossed := false
+	for i := 0; i < 600; i++ {
+		s.Step(time.Second)
+		state := s.GetCurrentState()
+		if math.IsNaN(state.Position.Latitude) || math.IsNaN(state.Position.Longitude) || math.IsNaN(state.Course) {
+			t.Fatalf("step %d: NaN in state %+v", i, state)
+		}
+		if state.Position.Latitude > 90 {
+			t.Fatalf("step %d: latitude %v past the pole", i, state.Position.Latitude)
+		}
+		if state.Course == 180 {
+			crossed = true
+		}
+	}
+
+	// Over the pole the vessel comes down the opposite meridian heading south
+	state := s.GetCurrentState()
+	if !crossed || state.Position.Latitude >= 89.98 {
+		t.Fatalf("did not cross the pole: course %v, latitude %v", state.Course, state.Position.Latitude)
+	}
+	if math.Abs(state.Position.Longitude-(-150)) > 0.01 {
+		t.Errorf("longitude after the pole %v, want -150", state.Position.Longitude)
+	}
+}
+
+func TestAntimeridianTakesShortWay(t *testing.T) {
+	tests := []struct {
+		name       string
+		lat1, lon1 float64
+		lat2, lon2 float64
+		wantCourse float64
+		maxNM      float64
+	}{
+		{"eastbound on the equator", 0, 179, 0, -179, 90, 121},
+		{"westbound on the equator", 0, -179, 0, 179, 270, 121},
+		{"eastbound at 40N", 40, 179.5, 40, -179.5, 90, 47},
+		{"exactly on the meridian", -20, 180, -20, -179, 90, 57},
+	}
+
+	s := newTestSimulator(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			course := s.calculateCourse(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if s.courseDifference(course, tt.wantCourse) > 0.5 {
+				t.Errorf("course %.2f, want about %.0f", course, tt.wantCourse)
+			}
+			if distance := s.calculateDistance(tt.lat1, tt.lon1, tt.lat2, tt.lon2); distance > tt.maxNM {
+				t.Errorf("distance %.1f NM, want under %.0f", distance, tt.maxNM)
+			}
+		})
+	}
+
+	// A small step east from just short of the meridian wraps to west longitude
+	lat, lon := s.calculateNewPosition(0, 179.99, 90, 1)
+	if lon > -179.98 || lon < -180 || math.Abs(lat) > 1e-6 {
+		t.Errorf("step across the meridian ended at %.5f, %.5f", lat, lon)
+	}
+}
+
+func TestPacificCrossingRoute(t *testing.T) {
+	s := newTestSimulator(t)
+
+	route := testRoute([2]float64{35, 179.5}, [2]float64{35.2, -179.8}, [2]float64{35.3, -179.2})
+	if err := s.SetRoute(route, 20); err != nil {
+		t.Fatalf("SetRoute: %v", err)
+	}
+
+	// About 50 NM: a little over two and a half hours at 20 knots the short way
+	for i := 0; i < 4*3600 && !s.routeComplete; i++ {
+		s.Step(time.Second)
+		if lon := s.GetCurrentState().Position.Longitude; lon > -179 && lon < 179 {
+			t.Fatalf("step %d: longitude %.3f, sailing the long way round", i, lon)
+		}
+	}
+	if !s.routeComplete {
+		t.Error("route not completed")
+	}
+}
@@ -0,0 +1,190 @@
+package nmea
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GNSS system IDs, as carried in the NMEA 4.10 GSA system-ID field
+const (
+	SystemGPS     = 1
+	SystemGLONASS = 2
+	SystemGalileo = 3
+	SystemBeiDou  = 4
+)
+
+// systemTalkers maps GNSS system IDs to the talker ID of their GSV sentences
+var systemTalkers = map[int]string{
+	SystemGPS:     "GP",
+	SystemGLONASS: "GL",
+	SystemGalileo: "GA",
+	SystemBeiDou:  "GB",
+}
+
+// ValidateGNSSSystems checks a list of GNSS system IDs
+func ValidateGNSSSystems(systems []int) error {
+	seen := make(map[int]bool, len(systems))
+	for _, system := range systems {
+		if system < SystemGPS || system > SystemBeiDou {
+			return fmt.Errorf("invalid GNSS system ID %d: use 1 (GPS) to 4 (BeiDou)", system)
+		}
+		if seen[system] {
+			return fmt.Errorf("GNSS system ID %d listed twice", system)
+		}
+		seen[system] = true
+	}
+	return nil
+}
+
+// SetGNSSSystems sets the constellations the satellites in view are shared
+// between. With systems set, one GNGSA sentence is sent per system carrying
+// its system ID; with none, a single GPGSA sentence as before NMEA 4.10
+func (s *Simulator) SetGNSSSystems(systems []int) error {
+	if err := ValidateGNSSSystems(systems); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gnssSystems = append([]int(nil), systems...)
+	s.sky = assignSystems(skyForCount(len(s.sky)), s.gnssSystems)
+	return nil
+}
+
+// SetConstellationMix sets how many satellites of each system are in view and
+// used in the fix. Each system in view then reports its own GSV sentences
+// under its talker ID and its own GSA sentence. A GPS-only mix restores the
+// single-constellation GPGSA and GPGSV output
+func (s *Simulator) SetConstellationMix(gps, glonass, galileo, beidou int) error {
+	counts := []int{gps, glonass, galileo, beidou}
+	total := 0
+	for _, count := range counts {
+		if count < 0 {
+			return fmt.Errorf("satellite counts cannot be negative")
+		}
+		total += count
+	}
+	if total > MaxSatellites {
+		return fmt.Errorf("total satellite count must be at most %d", MaxSatellites)
+	}
+
+	var systems, assignment []int
+	for i, count := range counts {
+		if count == 0 {
+			continue
+		}
+		systems = append(systems, SystemGPS+i)
+		for n := 0; n < count; n++ {
+			assignment = append(assignment, SystemGPS+i)
+		}
+	}
+	if len(systems) == 1 && systems[0] == SystemGPS {
+		systems = nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gnssSystems = systems
+	s.sky = numberSystems(skyForCount(total), assignment)
+	s.state.Satellites = total
+	return nil
+}
+
+// assignSystems shares the sky between systems in turn, numbering each
+// satellite within its system. The sky is returned unchanged without systems
+func assignSystems(sky []satellite, systems []int) []satellite {
+	if len(systems) == 0 {
+		return sky
+	}
+
+	assignment := make([]int, len(sky))
+	for i := range sky {
+		assignment[i] = systems[i%len(systems)]
+	}
+	return numberSystems(sky, assignment)
+}
+
+// numberSystems sets the system of each satellite from assignment, numbering
+// the satellites of each system in turn
+func numberSystems(sky []satellite, assignment []int) []satellite {
+	counts := make(map[int]int)
+	for i, system := range assignment {
+		counts[system]++
+		sky[i].System = system
+		sky[i].PRN = systemPRN(system, counts[system])
+	}
+	return sky
+}
+
+// systemPRN returns the NMEA satellite ID of the nth satellite of a system.
+// GLONASS satellites are numbered from 65; the others from 1
+func systemPRN(system, n int) int {
+	if system == SystemGLONASS {
+		return 64 + n
+	}
+	return n
+}
+
+// gsaSentences returns the GSA sentences for a transmit cycle: one per GNSS
+// system when systems are set, otherwise the single GPGSA sentence
+func (s *Simulator) gsaSentences(state NavigationState, sky []satellite) []string {
+	if len(s.gnssSystems) == 0 {
+		return []string{s.generateGSA(state, sky)}
+	}
+
+	sentences := make([]string, 0, len(s.gnssSystems))
+	for _, system := range s.gnssSystems {
+		sentences = append(sentences, s.generateSystemGSA(state, sky, system))
+	}
+	return sentences
+}
+
+// gsvSentences returns the GSV sentences for a transmit cycle: a sequence per
+// GNSS system under its own talker ID when systems are set, otherwise GPGSV
+// for the whole sky
+func (s *Simulator) gsvSentences(state NavigationState, sky []satellite) []string {
+	if len(s.gnssSystems) == 0 {
+		return s.generateGSV(state, sky)
+	}
+
+	var sentences []string
+	for _, system := range s.gnssSystems {
+		var inView []satellite
+		for _, sat := range sky {
+			if sat.System == system {
+				inView = append(inView, sat)
+			}
+		}
+		sentences = append(sentences, s.generateTalkerGSV(systemTalkers[system], inView)...)
+	}
+	return sentences
+}
+
+// generateSystemGSA generates an NMEA 4.10 GSA sentence listing the fix
+// satellites of one system, ending with the system ID
+func (s *Simulator) generateSystemGSA(state NavigationState, sky []satellite, system int) string {
+	const prnSlots = 12
+
+	if state.FixQuality == 0 {
+		return s.addChecksum(fmt.Sprintf("GNGSA,A,1,%s,,,,%d", strings.Repeat(",", prnSlots-1), system))
+	}
+
+	// The fix uses the first state.Satellites satellites of the sky
+	prns := make([]string, 0, prnSlots)
+	for i, sat := range sky {
+		if i >= state.Satellites || len(prns) == prnSlots {
+			break
+		}
+		if sat.System == system {
+			prns = append(prns, fmt.Sprintf("%02d", sat.PRN))
+		}
+	}
+	for len(prns) < prnSlots {
+		prns = append(prns, "")
+	}
+
+	sentence := fmt.Sprintf("GNGSA,A,3,%s,%.1f,%.1f,%.1f,%d",
+		strings.Join(prns, ","), state.HDOP*1.5, state.HDOP, state.HDOP*0.8, system) // PDOP, HDOP, VDOP
+
+	return s.addChecksum(sentence)
+}
@@ -0,0 +1,125 @@
+// maxlength.go - Keeping sentences within the NMEA 0183 length limit
+package nmea
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxSentenceLength is the NMEA 0183 limit on a sentence, counting the
+// leading "$" or "!" and the CR LF terminator
+const maxSentenceLength = 82
+
+// Handling of sentences over the length limit
+const (
+	SentenceLengthTruncate = "truncate" // shorten the longest text fields until the sentence fits
+	SentenceLengthWarn     = "warn"     // send as generated, but record the sentence (default)
+	SentenceLengthAllow    = "allow"    // send as generated
+)
+
+// SentenceLengthReport describes the over-length sentences seen since the
+// simulator was created
+type SentenceLengthReport struct {
+	Mode       string `json:"mode"`
+	Count      int    `json:"count"`                // over-length sentences generated
+	Truncated  int    `json:"truncated"`            // of those, how many were shortened to fit
+	Last       string `json:"last,omitempty"`       // the most recent, as generated
+	LastLength int    `json:"lastLength,omitempty"` // its length including CR LF
+}
+
+// ValidateSentenceLengthMode checks an over-length handling mode is known
+func ValidateSentenceLengthMode(mode string) error {
+	switch mode {
+	case SentenceLengthTruncate, SentenceLengthWarn, SentenceLengthAllow:
+		return nil
+	default:
+		return fmt.Errorf("invalid sentence length mode %q: use %q, %q or %q",
+			mode, SentenceLengthTruncate, SentenceLengthWarn, SentenceLengthAllow)
+	}
+}
+
+// SetSentenceLengthMode sets how sentences over the 82-character limit are
+// handled, such as proprietary sentences with long values. The default is
+// "warn", which sends sentences unchanged
+func (s *Simulator) SetSentenceLengthMode(mode string) error {
+	if err := ValidateSentenceLengthMode(mode); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lengthMode = mode
+	return nil
+}
+
+// GetSentenceLengthReport returns the over-length sentences seen so far
+func (s *Simulator) GetSentenceLengthReport() SentenceLengthReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report := s.lengthReport
+	report.Mode = s.lengthMode
+	return report
+}
+
+// enforceSentenceLength applies the over-length mode to a cycle's sentences.
+// The caller must hold the write lock
+func (s *Simulator) enforceSentenceLength(sentences []string) []string {
+	for i, sentence := range sentences {
+		length := len(sentence) + len("\r\n")
+		if length <= maxSentenceLength {
+			continue
+		}
+
+		s.lengthReport.Count++
+		s.lengthReport.Last = sentence
+		s.lengthReport.LastLength = length
+
+		if s.lengthMode != SentenceLengthTruncate {
+			continue
+		}
+		if truncated, ok := s.truncateSentence(sentence); ok {
+			sentences[i] = truncated
+			s.lengthReport.Truncated++
+		}
+	}
+	return sentences
+}
+
+// truncateSentence shortens the longest text fields of a sentence, one
+// character at a time, until it fits the length limit, then recomputes the
+// checksum. The address field, numeric fields and single-character fields are
+// never shortened, as cutting them would change their meaning, and nor are
+// encapsulated "!" sentences such as AIS, whose payload would be corrupted. It
+// reports false, returning the sentence unchanged, when it can't be made to fit
+func (s *Simulator) truncateSentence(sentence string) (string, bool) {
+	if !strings.HasPrefix(sentence, "$") {
+		return sentence, false
+	}
+	body := sentence[1:]
+	if i := strings.LastIndex(body, "*"); i >= 0 {
+		body = body[:i]
+	}
+
+	fields := strings.Split(body, ",")
+	for len(body) > maxSentenceBody {
+		longest := -1
+		for j := 1; j < len(fields); j++ {
+			if _, err := strconv.ParseFloat(fields[j], 64); err == nil {
+				continue
+			}
+			if longest < 0 || len(fields[j]) > len(fields[longest]) {
+				longest = j
+			}
+		}
+		// Single characters are units, hemispheres and status flags
+		if longest < 0 || len(fields[longest]) <= 1 {
+			return sentence, false
+		}
+		fields[longest] = fields[longest][:len(fields[longest])-1]
+		body = strings.Join(fields, ",")
+	}
+
+	return s.addChecksum(body), true
+}
@@ -0,0 +1,150 @@
+package nmea
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSentenceLengthEnforcement(t *testing.T) {
+	long := strings.Repeat("NAME", 20)
+	ais := "!" + strings.TrimPrefix((&Simulator{}).addChecksum("AIVDM,1,1,,A,"+strings.Repeat("0", 80)+",0"), "$")
+
+	tests := []struct {
+		name          string
+		mode          string // "" leaves the default
+		sentence      string
+		wantChanged   bool
+		wantCount     int
+		wantTruncated int
+	}{
+		{"fits", SentenceLengthTruncate, "$GPWPL,5130.4411,N,00007.6655,E,HARBOUR", false, 0, 0},
+		{"exactly 82", SentenceLengthTruncate, "$GPTXT," + strings.Repeat("X", 82-len("$GPTXT,*00\r\n")), false, 0, 0},
+		{"default warns", "", "$GPWPL,5130.4411,N,00007.6655,E," + long, false, 1, 0},
+		{"truncated", SentenceLengthTruncate, "$GPWPL,5130.4411,N,00007.6655,E," + long, true, 1, 1},
+		{"warned", SentenceLengthWarn, "$GPWPL,5130.4411,N,00007.6655,E," + long, false, 1, 0},
+		{"allowed", SentenceLengthAllow, "$GPWPL,5130.4411,N,00007.6655,E," + long, false, 1, 0},
+		{"AIS never cut", SentenceLengthTruncate, ais, false, 1, 0},
+		{"numbers never cut", SentenceLengthTruncate, "$PSIMN," + strings.Repeat("1234567.890,", 7) + "1", false, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+			if tt.mode != "" {
+				if err := s.SetSentenceLengthMode(tt.mode); err != nil {
+					t.Fatalf("SetSentenceLengthMode: %v", err)
+				}
+			}
+
+			sentence := tt.sentence
+			if strings.HasPrefix(sentence, "$") {
+				sentence = s.addChecksum(sentence[1:])
+			}
+			s.mu.Lock()
+			got := s.enforceSentenceLength([]string{sentence})[0]
+			s.mu.Unlock()
+
+			if changed := got != sentence; changed != tt.wantChanged {
+				t.Errorf("sentence changed = %v, want %v: %s", changed, tt.wantChanged, got)
+			}
+			if tt.wantChanged {
+				if err := checkSentence(got); err != nil {
+					t.Errorf("%s: %v", got, err)
+				}
+				if fields, original := sentenceFields(got), sentenceFields(sentence); len(fields) != len(original) || fields[0] != original[0] {
+					t.Errorf("fields not kept: %s", got)
+				}
+			}
+
+			report := s.GetSentenceLengthReport()
+			if report.Count != tt.wantCount || report.Truncated != tt.wantTruncated {
+				t.Errorf("report counted %d, truncated %d; want %d and %d", report.Count, report.Truncated, tt.wantCount, tt.wantTruncated)
+			}
+			if tt.wantCount > 0 && (report.Last != sentence || report.LastLength != len(sentence)+2) {
+				t.Errorf("report last %q (%d), want the original sentence", report.Last, report.LastLength)
+			}
+			if tt.mode == "" && report.Mode != SentenceLengthWarn {
+				t.Errorf("default mode %q, want %q", report.Mode, SentenceLengthWarn)
+			}
+		})
+	}
+}
+
+func TestTruncateSentenceShortensLongestField(t *testing.T) {
+	s := newTestSimulator(t)
+
+	sentence := s.addChecksum("PSIMX," + strings.Repeat("A", 50) + "," + strings.Repeat("B", 40) + ",KEEP,123.45")
+	got, ok := s.truncateSentence(sentence)
+	if !ok {
+		t.Fatalf("not truncated: %s", got)
+	}
+
+	if err := checkSentence(got); err != nil {
+		t.Fatalf("%s: %v", got, err)
+	}
+	fields := sentenceFields(got)
+	if fields[0] != "$PSIMX" || fields[3] != "KEEP" || fields[4] != "123.45" {
+		t.Errorf("address, short or numeric field changed: %s", got)
+	}
+	// The two long fields are evened out before either is cut further
+	if diff := len(fields[1]) - len(fields[2]); diff < -1 || diff > 1 {
+		t.Errorf("fields of %d and %d characters, want the longest cut first", len(fields[1]), len(fields[2]))
+	}
+}
+
+func TestLongWaypointNamesFitLengthLimit(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	transport := funcTransport(func(data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, strings.Split(strings.TrimSuffix(string(data), "\r\n"), "\r\n")...)
+		return nil
+	})
+
+	s, err := NewSimulatorWithTransport(SimulatorConfig{TransmitRate: time.Hour}, transport)
+	if err != nil {
+		t.Fatalf("NewSimulatorWithTransport: %v", err)
+	}
+	defer s.Close()
+	if err := s.SetRouteBroadcast(true, 0); err != nil {
+		t.Fatalf("SetRouteBroadcast: %v", err)
+	}
+
+	route := testRoute([2]float64{50, 0}, [2]float64{50.1, 0}, [2]float64{50.2, 0.1})
+	route.Name = "A ROUTE NAME LONGER THAN ANY SENTENCE FIELD SHOULD EVER BE"
+	for i := range route.Waypoints {
+		route.Waypoints[i].Name = strings.Repeat(string(rune('A'+i)), 60) + " HARBOUR APPROACH"
+	}
+	if err := s.SetRoute(route, 10); err != nil {
+		t.Fatalf("SetRoute: %v", err)
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	s.Stop()
+	s.transmitNMEASentences()
+
+	mu.Lock()
+	defer mu.Unlock()
+	var wpl, rte int
+	for _, sentence := range sent {
+		if err := checkSentence(sentence); err != nil {
+			t.Errorf("%s: %v", sentence, err)
+		}
+		switch {
+		case strings.HasPrefix(sentence, "$GPWPL,"):
+			wpl++
+		case strings.HasPrefix(sentence, "$GPRTE,"):
+			rte++
+		}
+	}
+	if wpl != len(route.Waypoints) || rte == 0 {
+		t.Errorf("sent %d WPL and %d RTE sentences, want %d WPL and some RTE", wpl, rte, len(route.Waypoints))
+	}
+	if report := s.GetSentenceLengthReport(); report.Count != 0 {
+		t.Errorf("%d over-length sentences, last %s", report.Count, report.Last)
+	}
+}
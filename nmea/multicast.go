@@ -0,0 +1,42 @@
+package nmea
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// setMulticastLoopback sets whether multicast datagrams sent on conn are looped
+// back to listeners on the sending host. It has no effect for unicast addresses
+func setMulticastLoopback(conn *net.UDPConn, addr *net.UDPAddr, enabled bool) error {
+	if !addr.IP.IsMulticast() {
+		return nil
+	}
+
+	var err error
+	if addr.IP.To4() != nil {
+		err = ipv4.NewPacketConn(conn).SetMulticastLoopback(enabled)
+	} else {
+		err = ipv6.NewPacketConn(conn).SetMulticastLoopback(enabled)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set multicast loopback: %w", err)
+	}
+
+	return nil
+}
+
+// SetMulticastLoopback sets whether the sending host receives its own
+// multicast sentences
+func (s *Simulator) SetMulticastLoopback(enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	udp, ok := s.transport.(*UDPTransport)
+	if !ok {
+		return fmt.Errorf("multicast loopback only applies to UDP output")
+	}
+	return udp.SetMulticastLoopback(enabled)
+}
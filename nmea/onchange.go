@@ -0,0 +1,106 @@
+package nmea
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Transmit modes
+const (
+	TransmitPeriodic = "periodic" // send every sentence each cycle
+	TransmitOnChange = "onchange" // send a sentence only when its values change
+)
+
+// DefaultHeartbeat is how long an unchanged sentence is held back in
+// change-driven mode before being sent anyway
+const DefaultHeartbeat = 5 * time.Second
+
+// sentenceTimeFields lists the fields of each sentence type holding the time
+// or date, which change every cycle and so don't count as a change in value
+var sentenceTimeFields = map[string][]int{
+	"GGA": {1},
+	"RMC": {1, 9},
+	"GLL": {5},
+	"GBS": {1},
+}
+
+// sentRecord is the last transmission of a sentence in change-driven mode
+type sentRecord struct {
+	fingerprint string
+	at          time.Time
+}
+
+// ValidateTransmitMode checks that mode is a supported transmit mode
+func ValidateTransmitMode(mode string) error {
+	switch mode {
+	case TransmitPeriodic, TransmitOnChange:
+		return nil
+	}
+	return fmt.Errorf("invalid transmit mode %q: use %q or %q", mode, TransmitPeriodic, TransmitOnChange)
+}
+
+// SetTransmitMode sets whether every sentence is sent each cycle
+// (TransmitPeriodic, the default) or only when its values have changed
+// (TransmitOnChange), subject to the heartbeat
+func (s *Simulator) SetTransmitMode(mode string) error {
+	if err := ValidateTransmitMode(mode); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transmitMode = mode
+	s.lastSent = make(map[string]sentRecord)
+	return nil
+}
+
+// SetHeartbeat sets the longest a sentence goes unsent in change-driven mode,
+// so consumers don't time out while nothing changes
+func (s *Simulator) SetHeartbeat(heartbeat time.Duration) error {
+	if heartbeat <= 0 {
+		return fmt.Errorf("heartbeat must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeat = heartbeat
+	return nil
+}
+
+// changedSentences filters a cycle's sentences down to those to send in
+// change-driven mode: ones whose values differ from when they were last sent,
+// or that have been held back for a heartbeat. The caller must hold the write lock
+func (s *Simulator) changedSentences(sentences []string, now time.Time) []string {
+	if s.transmitMode != TransmitOnChange {
+		return sentences
+	}
+
+	changed := make([]string, 0, len(sentences))
+	occurrences := make(map[string]int)
+	for _, sentence := range sentences {
+		fields := strings.Split(strings.SplitN(sentence, "*", 2)[0], ",")
+
+		// Sentences sent in several parts, such as GSV, are tracked per part
+		occurrences[fields[0]]++
+		key := fmt.Sprintf("%s#%d", fields[0], occurrences[fields[0]])
+
+		if len(fields[0]) > 3 {
+			for _, i := range sentenceTimeFields[fields[0][3:]] {
+				if i < len(fields) {
+					fields[i] = ""
+				}
+			}
+		}
+		fingerprint := strings.Join(fields, ",")
+
+		last, sent := s.lastSent[key]
+		if sent && last.fingerprint == fingerprint && now.Sub(last.at) < s.heartbeat {
+			continue
+		}
+
+		s.lastSent[key] = sentRecord{fingerprint: fingerprint, at: now}
+		changed = append(changed, sentence)
+	}
+	return changed
+}
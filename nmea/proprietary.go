@@ -0,0 +1,106 @@
+package nmea
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// placeholderPattern matches {name} placeholders in proprietary sentence templates
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// proprietaryFields maps template placeholders to their values for a state
+var proprietaryFields = map[string]func(s *Simulator, state NavigationState) string{
+	"time":      func(s *Simulator, state NavigationState) string { return state.Position.Timestamp.Format("150405.00") },
+	"date":      func(s *Simulator, state NavigationState) string { return state.Position.Timestamp.Format("020106") },
+	"lat":       func(s *Simulator, state NavigationState) string { return s.formatLatitude(state.Position.Latitude) },
+	"lon":       func(s *Simulator, state NavigationState) string { return s.formatLongitude(state.Position.Longitude) },
+	"latitude":  func(s *Simulator, state NavigationState) string { return fmt.Sprintf("%.6f", state.Position.Latitude) },
+	"longitude": func(s *Simulator, state NavigationState) string { return fmt.Sprintf("%.6f", state.Position.Longitude) },
+	"speed":     func(s *Simulator, state NavigationState) string { return fmt.Sprintf("%.1f", state.Speed) },
+	"speed_kmh": func(s *Simulator, state NavigationState) string { return fmt.Sprintf("%.1f", state.Speed*1.852) },
+	"course":    func(s *Simulator, state NavigationState) string { return fmt.Sprintf("%.1f", state.Course) },
+	"altitude":  func(s *Simulator, state NavigationState) string { return fmt.Sprintf("%.1f", state.Altitude) },
+	"altitude_ft": func(s *Simulator, state NavigationState) string {
+		return fmt.Sprintf("%.0f", state.Altitude*feetPerMeter)
+	},
+	"satellites":  func(s *Simulator, state NavigationState) string { return fmt.Sprintf("%02d", state.Satellites) },
+	"hdop":        func(s *Simulator, state NavigationState) string { return fmt.Sprintf("%.1f", state.HDOP) },
+	"fix_quality": func(s *Simulator, state NavigationState) string { return fmt.Sprintf("%d", state.FixQuality) },
+	"magvar":      func(s *Simulator, state NavigationState) string { return fmt.Sprintf("%.1f", state.MagneticVar) },
+}
+
+// Version is the simulator version reported in the simulation marker
+const Version = "1.0.0"
+
+// simulationMarker identifies the feed as simulated, so loggers and mixed
+// test setups can't mistake it for live navigation data
+const simulationMarker = "PSIMU," + Version + ",SIMULATION"
+
+// EnableSimulationMarker sets whether a $PSIMU sentence marking the feed as
+// simulated is sent each cycle
+func (s *Simulator) EnableSimulationMarker(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.simulationMarker = enabled
+}
+
+// decimalDegreesTemplate is the debug sentence giving the position in signed
+// decimal degrees, north and east positive, for easy reading in logs
+const decimalDegreesTemplate = "PSIMDD,{time},{latitude},{longitude}"
+
+// EnableDecimalDegreesDebugSentence sets whether a $PSIMDD sentence with the
+// position in signed decimal degrees is sent each cycle alongside the
+// standard sentences
+func (s *Simulator) EnableDecimalDegreesDebugSentence(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decimalDegreesDebug = enabled
+}
+
+// AddProprietarySentence adds a proprietary sentence template transmitted every
+// cycle, e.g. "$PGRMZ,{altitude_ft},f,3". Placeholders are substituted from the
+// navigation state and the checksum is added automatically
+func (s *Simulator) AddProprietarySentence(template string) error {
+	body := strings.TrimPrefix(strings.TrimSpace(template), "$")
+	if i := strings.Index(body, "*"); i >= 0 {
+		body = body[:i]
+	}
+
+	if !strings.HasPrefix(body, "P") || len(body) < 2 {
+		return fmt.Errorf("proprietary sentences must start with $P")
+	}
+
+	for _, match := range placeholderPattern.FindAllStringSubmatch(body, -1) {
+		if _, ok := proprietaryFields[match[1]]; !ok {
+			return fmt.Errorf("unknown placeholder {%s}", match[1])
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, err := sanitizeSentenceBody(body, s.strictASCII)
+	if err != nil {
+		return err
+	}
+
+	s.proprietary = append(s.proprietary, body)
+	return nil
+}
+
+// ClearProprietarySentences removes all proprietary sentence templates
+func (s *Simulator) ClearProprietarySentences() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proprietary = nil
+}
+
+// generateProprietary generates a sentence from a proprietary template
+func (s *Simulator) generateProprietary(template string, state NavigationState) string {
+	sentence := placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return proprietaryFields[placeholder[1:len(placeholder)-1]](s, state)
+	})
+
+	return s.addChecksum(sentence)
+}
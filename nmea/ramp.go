@@ -0,0 +1,62 @@
+package nmea
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxSpeedRamp bounds the warm-up ramp; longer ramps are better modelled by
+// changing the speed during the run
+const MaxSpeedRamp = 60 * time.Second
+
+// ValidateSpeedRamp checks a warm-up ramp duration
+func ValidateSpeedRamp(ramp time.Duration) error {
+	if ramp < 0 || ramp > MaxSpeedRamp {
+		return fmt.Errorf("speed ramp must be between 0 and %d seconds", int(MaxSpeedRamp/time.Second))
+	}
+	return nil
+}
+
+// SetSpeedRamp sets how long the vessel takes to accelerate from rest to its
+// speed when the simulation starts. Zero starts at full speed
+func (s *Simulator) SetSpeedRamp(ramp time.Duration) error {
+	if err := ValidateSpeedRamp(ramp); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.speedRamp = ramp
+	return nil
+}
+
+// startSpeedRamp brings the vessel to rest so it can accelerate to its set
+// speed. Caller must hold the write lock
+func (s *Simulator) startSpeedRamp() {
+	// A ramp cut short by stopping resumes towards its original speed
+	target := s.state.Speed
+	if s.rampTarget > target {
+		target = s.rampTarget
+	}
+
+	s.rampTarget = 0
+	if s.speedRamp <= 0 || target <= 0 {
+		return
+	}
+	s.rampTarget = target
+	s.state.Speed = 0
+}
+
+// stepSpeedRamp accelerates dt's worth towards the ramp target. Caller must
+// hold the write lock
+func (s *Simulator) stepSpeedRamp(dt time.Duration) {
+	if s.rampTarget <= 0 {
+		return
+	}
+
+	s.state.Speed += s.rampTarget * dt.Seconds() / s.speedRamp.Seconds()
+	if s.state.Speed >= s.rampTarget {
+		s.state.Speed = s.rampTarget
+		s.rampTarget = 0
+	}
+}
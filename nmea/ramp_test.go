@@ -0,0 +1,67 @@
+package nmea
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSpeedRampAtStart(t *testing.T) {
+	tests := []struct {
+		name  string
+		ramp  time.Duration
+		speed float64
+		want  []float64 // speed after each one-second step
+	}{
+		{"no ramp", 0, 25, []float64{25, 25}},
+		{"five seconds to 25 knots", 5 * time.Second, 25, []float64{5, 10, 15, 20, 25, 25}},
+		{"uneven ramp", 4 * time.Second, 10, []float64{2.5, 5, 7.5, 10, 10}},
+		{"shorter than a step", 500 * time.Millisecond, 12, []float64{12, 12}},
+		{"at rest", 10 * time.Second, 0, []float64{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+			if err := s.SetSpeedRamp(tt.ramp); err != nil {
+				t.Fatalf("SetSpeedRamp: %v", err)
+			}
+			s.SetPosition(50, -1, tt.speed, 90)
+
+			s.mu.Lock()
+			s.startSpeedRamp()
+			s.mu.Unlock()
+
+			previous := 0.0
+			for i, want := range tt.want {
+				s.Step(time.Second)
+				got := s.GetCurrentState().Speed
+				if math.Abs(got-want) > 1e-9 {
+					t.Errorf("after %ds: speed %.2f, want %.2f", i+1, got, want)
+				}
+				if got < previous {
+					t.Errorf("after %ds: speed fell from %.2f to %.2f", i+1, previous, got)
+				}
+				previous = got
+			}
+		})
+	}
+}
+
+func TestSpeedRampValidation(t *testing.T) {
+	tests := []struct {
+		ramp    time.Duration
+		wantErr bool
+	}{
+		{0, false},
+		{MaxSpeedRamp, false},
+		{-time.Second, true},
+		{MaxSpeedRamp + time.Second, true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateSpeedRamp(tt.ramp); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateSpeedRamp(%s) = %v, want error %v", tt.ramp, err, tt.wantErr)
+		}
+	}
+}
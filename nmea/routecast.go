@@ -0,0 +1,134 @@
+package nmea
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// routeSentencesPerCycle limits how many queued route sentences go out with
+// each transmit cycle, so a large route doesn't flood consumers in one burst
+const routeSentencesPerCycle = 10
+
+// SetRouteBroadcast sets when the route is transmitted as WPL sentences: once
+// when the simulation starts, and/or every interval (0 disables the periodic
+// broadcast). Queued sentences are paced across transmit cycles
+func (s *Simulator) SetRouteBroadcast(atStart bool, interval time.Duration) error {
+	if interval < 0 {
+		return fmt.Errorf("route broadcast interval cannot be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routeAtStart = atStart
+	s.routeInterval = interval
+	return nil
+}
+
+// queueRouteBroadcast queues the route sentences for transmission, unless a
+// broadcast is already in progress. The caller must hold the write lock
+func (s *Simulator) queueRouteBroadcast() {
+	if s.route == nil || len(s.routeQueue) > 0 {
+		return
+	}
+
+	ids := routeWaypointIDs(s.route)
+	for i, wp := range s.route.Waypoints {
+		s.routeQueue = append(s.routeQueue, s.generateWPL(wp, ids[i]))
+	}
+	s.routeQueue = append(s.routeQueue, s.generateRTE(s.route.Name, ids)...)
+	s.lastRouteBroadcast = time.Now()
+}
+
+// nextRouteSentences returns the route sentences due this cycle, starting a
+// periodic broadcast when one is due. The caller must hold the write lock
+func (s *Simulator) nextRouteSentences() []string {
+	if s.routeInterval > 0 && time.Since(s.lastRouteBroadcast) >= s.routeInterval {
+		s.queueRouteBroadcast()
+	}
+
+	count := len(s.routeQueue)
+	if count > routeSentencesPerCycle {
+		count = routeSentencesPerCycle
+	}
+
+	batch := s.routeQueue[:count:count]
+	s.routeQueue = s.routeQueue[count:]
+	return batch
+}
+
+// generateWPL generates a WPL (Waypoint Location) sentence for a route waypoint
+func (s *Simulator) generateWPL(wp Waypoint, id string) string {
+	sentence := fmt.Sprintf("GPWPL,%s,%s,%s",
+		s.formatLatitude(wp.Latitude), s.formatLongitude(wp.Longitude), id)
+
+	return s.addChecksum(sentence)
+}
+
+// RTE sentence limits
+const (
+	// maxSentenceBody is the longest sentence body that keeps a sentence within
+	// the NMEA 82-character limit once "$", "*hh" and CR LF are added
+	maxSentenceBody = 82 - len("$") - len("*hh") - len("\r\n")
+	// maxRTEWaypointID is the longest waypoint identifier placed in WPL and
+	// RTE sentences; longer identifiers are truncated
+	maxRTEWaypointID = 10
+	// maxRTERouteID is the longest route identifier placed in an RTE sentence
+	maxRTERouteID = 10
+)
+
+// routeWaypointIDs returns the identifiers used for the route's waypoints in
+// WPL and RTE sentences, so the two can be matched up. A name that truncates
+// to the same identifier as an earlier waypoint falls back to the RTZ ID
+func routeWaypointIDs(route *RTZRoute) []string {
+	ids := make([]string, len(route.Waypoints))
+	seen := make(map[string]bool)
+	for i, wp := range route.Waypoints {
+		id := truncate(nmeaField(wp.Identifier()), maxRTEWaypointID)
+		if seen[id] {
+			id = truncate(nmeaField(wp.ID), maxRTEWaypointID)
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+	return ids
+}
+
+// generateRTE generates the RTE (Routes) sentences listing waypoint ids in
+// route order, as a complete route split across as many sentences as the
+// length limit requires
+func (s *Simulator) generateRTE(routeName string, ids []string) []string {
+	routeID := truncate(nmeaField(routeName), maxRTERouteID)
+
+	// Leave room in each sentence for two-digit sentence counts
+	prefixLen := len(fmt.Sprintf("GPRTE,99,99,c,%s", routeID))
+
+	var groups [][]string
+	var group []string
+	length := prefixLen
+	for _, id := range ids {
+		if len(group) > 0 && length+1+len(id) > maxSentenceBody {
+			groups = append(groups, group)
+			group, length = nil, prefixLen
+		}
+		group = append(group, id)
+		length += 1 + len(id)
+	}
+	groups = append(groups, group)
+
+	sentences := make([]string, len(groups))
+	for i, group := range groups {
+		sentence := fmt.Sprintf("GPRTE,%d,%d,c,%s,%s", len(groups), i+1, routeID, strings.Join(group, ","))
+		sentences[i] = s.addChecksum(sentence)
+	}
+
+	return sentences
+}
+
+// truncate shortens text to at most n bytes
+func truncate(text string, n int) string {
+	if len(text) > n {
+		return text[:n]
+	}
+	return text
+}
@@ -0,0 +1,85 @@
+package nmea
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetActiveRange restricts route following to waypoints start through end of
+// the loaded route, placing the vessel on waypoint start heading for the next
+// one. Indices always refer to the whole route; setting the full span
+// restores it
+func (s *Simulator) SetActiveRange(start, end int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	full := s.wholeRoute()
+	if full == nil {
+		return ErrNoRoute
+	}
+
+	count := len(full.Waypoints)
+	if start < 0 || end >= count {
+		return fmt.Errorf("%w: range must be within waypoints 0 to %d", ErrInvalidWaypoint, count-1)
+	}
+	if start >= end {
+		return fmt.Errorf("%w: range start must come before its end", ErrInvalidWaypoint)
+	}
+
+	if start == 0 && end == count-1 {
+		s.route = full
+		s.fullRoute = nil
+	} else {
+		s.route = full.withWaypoints(append([]Waypoint(nil), full.Waypoints[start:end+1]...))
+		s.fullRoute = full
+	}
+	s.rangeStart = start
+	s.originalRoute = nil
+	s.sourceIndex = nil
+	s.autoNavigate = true
+	s.turnRadius = 0
+
+	first, next := s.route.Waypoints[0], s.route.Waypoints[1]
+	s.state.Position = Position{
+		Latitude:  first.Latitude,
+		Longitude: first.Longitude,
+		Timestamp: time.Now().UTC(),
+	}
+	s.currentWaypoint = 1
+	s.routeComplete = false
+	s.arrivalLatch = -1
+	s.extensionLeft = 0
+	s.endDwell()
+	s.courseHold = false
+	s.state.Course = s.calculateCourse(first.Latitude, first.Longitude, next.Latitude, next.Longitude)
+	return nil
+}
+
+// wholeRoute returns the route as loaded, before any range restriction or
+// densifying. The caller must hold the lock
+func (s *Simulator) wholeRoute() *RTZRoute {
+	// Densifying within a range keeps the range's waypoints as the original
+	if s.fullRoute != nil {
+		return s.fullRoute
+	}
+	if s.originalRoute != nil {
+		return s.originalRoute
+	}
+	return s.route
+}
+
+// GetActiveRange returns the span of the whole route being followed, as
+// indices of its first and last waypoints
+func (s *Simulator) GetActiveRange() (start, end int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	route := s.originalRoute
+	if route == nil {
+		route = s.route
+	}
+	if route == nil {
+		return 0, 0
+	}
+	return s.rangeStart, s.rangeStart + len(route.Waypoints) - 1
+}
@@ -0,0 +1,64 @@
+package nmea
+
+import "testing"
+
+// testRTZ is a minimal two-waypoint RTZ route
+const testRTZ = `<?xml version="1.0" encoding="UTF-8"?>
+<route version="1.1" xmlns="http://www.cirm.org/RTZ/1/1">
+  <routeInfo routeName="Harbour approach"/>
+  <waypoints>
+    <waypoint id="1" name="Fairway"><position lat="50.80" lon="-1.10"/></waypoint>
+    <waypoint id="2" name="Berth"><position lat="50.85" lon="-1.05"/></waypoint>
+  </waypoints>
+</route>`
+
+func TestRTZLeadingBOMAndWhitespace(t *testing.T) {
+	const bom = "\xEF\xBB\xBF"
+
+	tests := []struct {
+		name   string
+		prefix string
+	}{
+		{"none", ""},
+		{"BOM", bom},
+		{"whitespace", " \t\r\n\n"},
+		{"BOM then whitespace", bom + "\r\n  "},
+		{"whitespace then BOM", "\n" + bom},
+		{"repeated BOM", bom + bom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+			if err := s.LoadRTZRoute([]byte(tt.prefix+testRTZ), 10); err != nil {
+				t.Fatalf("LoadRTZRoute: %v", err)
+			}
+
+			route := s.route
+			if route.Name != "Harbour approach" || len(route.Waypoints) != 2 {
+				t.Fatalf("loaded route %q with %d waypoints", route.Name, len(route.Waypoints))
+			}
+			if wp := route.Waypoints[0]; wp.Name != "Fairway" || wp.Latitude != 50.80 || wp.Longitude != -1.10 {
+				t.Errorf("first waypoint %+v", wp)
+			}
+		})
+	}
+}
+
+func TestTrimRTZPrefixKeepsContent(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"\xEF\xBB\xBF", ""},
+		{"<route/>", "<route/>"},
+		{"\xEF\xBB\xBF <route/> ", "<route/> "},
+		{"<route> \xEF\xBB\xBF</route>", "<route> \xEF\xBB\xBF</route>"},
+	}
+
+	for _, tt := range tests {
+		if got := string(TrimRTZPrefix([]byte(tt.in))); got != tt.want {
+			t.Errorf("TrimRTZPrefix(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,16 @@
 package nmea
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"math"
-	"net"
+	"math/rand"
+	"sort"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -18,51 +23,115 @@ type Position struct {
 
 // NavigationState holds the current navigation data
 type NavigationState struct {
-	Position    Position
-	Speed       float64 // knots
-	Course      float64 // degrees true
-	MagneticVar float64 // magnetic variation
-	FixQuality  int     // GPS fix quality (0=invalid, 1=GPS fix, 2=DGPS fix)
-	Satellites  int     // number of satellites
-	HDOP        float64 // horizontal dilution of precision
-	Altitude    float64 // altitude in meters
+	Position      Position
+	Speed         float64 // knots
+	Course        float64 // degrees true
+	MagneticVar   float64 // magnetic variation
+	FixQuality    int     // GPS fix quality (0=invalid, 1=GPS fix, 2=DGPS fix)
+	Satellites    int     // number of satellites
+	HDOP          float64 // horizontal dilution of precision
+	Altitude      float64 // altitude in meters
+	CurrentSet    float64 // direction the current flows toward, degrees true
+	CurrentDrift  float64 // current speed, knots
+	DGPSStationID int     // differential reference station ID (0-1023)
+	DGPSAge       float64 // age of differential corrections, seconds
 }
 
 // Waypoint represents a route waypoint
 type Waypoint struct {
-	ID        string
-	Latitude  float64
-	Longitude float64
+	ID           string
+	Name         string
+	Latitude     float64
+	Longitude    float64
+	SteeringMode string  // SteeringFlyOver or SteeringFlyBy
+	Radius       float64 // turn radius for fly-by waypoints, NM
+}
+
+// Waypoint steering modes
+const (
+	SteeringFlyOver = "flyover" // pass over the waypoint before turning
+	SteeringFlyBy   = "flyby"   // start the turn early, cutting the corner on the turn radius
+)
+
+// Identifier returns the name shown to navigation equipment for the waypoint,
+// falling back to its ID when the route gives no name
+func (wp Waypoint) Identifier() string {
+	if wp.Name != "" {
+		return wp.Name
+	}
+	return wp.ID
 }
 
 // RTZRoute represents a parsed RTZ route
 type RTZRoute struct {
-	Waypoints []Waypoint
+	Name       string
+	VesselName string
+	VesselIMO  string
+	Version    string // RTZ schema version, e.g. "1.0"
+	Waypoints  []Waypoint
+}
+
+// withWaypoints returns a copy of the route's metadata with new waypoints
+func (r *RTZRoute) withWaypoints(waypoints []Waypoint) *RTZRoute {
+	copied := *r
+	copied.Waypoints = waypoints
+	return &copied
 }
 
 // WaypointInfo contains current waypoint status information
 type WaypointInfo struct {
-	CurrentWaypoint int       `json:"currentWaypoint"`
-	TotalWaypoints  int       `json:"totalWaypoints"`
-	TargetWaypoint  *Waypoint `json:"targetWaypoint"`
-	DistanceToTarget float64  `json:"distanceToTarget"`
-	AutoNavigate    bool      `json:"autoNavigate"`
+	CurrentWaypoint   int       `json:"currentWaypoint"`
+	TotalWaypoints    int       `json:"totalWaypoints"`
+	TargetWaypoint    *Waypoint `json:"targetWaypoint"`
+	TargetName        string    `json:"targetName"` // waypoint name, or ID when unnamed
+	DistanceToTarget  float64   `json:"distanceToTarget"`
+	AutoNavigate      bool      `json:"autoNavigate"`
+	RemainingDistance float64   `json:"remainingDistance"` // to the final waypoint, NM
+	TotalDistance     float64   `json:"totalDistance"`     // whole route, NM
+	ProgressPercent   float64   `json:"progressPercent"`
+	Stalled           bool      `json:"stalled"`           // navigating a route with speed at zero
+	Warning           string    `json:"warning,omitempty"` // why the route isn't progressing
+	ArrivalRadius     float64   `json:"arrivalRadius"`     // distance at which a waypoint is reached, NM
+	Completed         bool      `json:"completed"`         // the final waypoint has been reached
+	ReachedWaypoint   *Waypoint `json:"reachedWaypoint"`   // the final waypoint, once completed
+	DwellRemaining    float64   `json:"dwellRemaining"`    // seconds left holding at a waypoint
+}
+
+// Speeds reports speed over ground against speed through the water
+type Speeds struct {
+	SOG   float64 `json:"sog"`   // speed over ground, knots
+	STW   float64 `json:"stw"`   // speed through water, knots
+	Delta float64 `json:"delta"` // SOG minus STW, the effect of the current
+}
+
+// SteeringDiagnostics reports how route-following steering arrived at the
+// heading applied in the last position update
+type SteeringDiagnostics struct {
+	Active          bool    `json:"active"`          // route following steered the last update
+	DesiredCourse   float64 `json:"desiredCourse"`   // bearing to the target waypoint, degrees
+	CrossTrackError float64 `json:"crossTrackError"` // NM, positive right of track
+	Correction      float64 `json:"correction"`      // cross-track correction applied, degrees
+	Heading         float64 `json:"heading"`         // course actually steered, degrees
 }
 
 // RTZ XML structures for parsing
 type rtzRoute struct {
 	XMLName   xml.Name      `xml:"route"`
+	Version   string        `xml:"version,attr"`
 	RouteInfo rtzRouteInfo  `xml:"routeInfo"`
 	Waypoints []rtzWaypoint `xml:"waypoints>waypoint"`
 }
 
 type rtzRouteInfo struct {
-	RouteName string `xml:"routeName,attr"`
+	RouteName  string `xml:"routeName,attr"`
+	VesselName string `xml:"vesselName,attr"`
+	VesselIMO  string `xml:"vesselIMO,attr"`
 }
 
 type rtzWaypoint struct {
 	ID       string      `xml:"id,attr"`
 	Name     string      `xml:"name,attr"`
+	Radius   float64     `xml:"radius,attr"`
 	Position rtzPosition `xml:"position"`
 }
 
@@ -71,50 +140,323 @@ type rtzPosition struct {
 	Longitude float64 `xml:"lon,attr"`
 }
 
+// Errors returned by simulator operations, for callers to test with errors.Is
+var (
+	ErrAlreadyRunning  = errors.New("simulation is already running")
+	ErrNotRunning      = errors.New("no simulation is running")
+	ErrNoRoute         = errors.New("no route loaded")
+	ErrInvalidWaypoint = errors.New("invalid waypoint")
+)
+
 // Simulator is the main NMEA simulator
 type Simulator struct {
-	mu              sync.RWMutex
-	state           NavigationState
-	conn            *net.UDPConn
-	multicastAddr   *net.UDPAddr
-	transmitRate    time.Duration
-	running         bool
-	stopChan        chan struct{}
-	route           *RTZRoute
-	currentWaypoint int
-	autoNavigate    bool
+	mu                  sync.RWMutex
+	state               NavigationState
+	transport           Transport
+	transmitRate        time.Duration
+	lineTerminator      string
+	running             bool
+	stopChan            chan struct{}
+	loops               sync.WaitGroup // simulation and transmission goroutines
+	route               *RTZRoute
+	currentWaypoint     int
+	autoNavigate        bool
+	rng                 *rand.Rand
+	multipath           bool
+	multipathProb       float64
+	acquisition         time.Duration
+	startProfile        startProfile // how satellites and fix quality climb during acquisition
+	startTime           time.Time
+	batchDatagram       bool
+	sky                 []satellite
+	dynamicSky          bool
+	lastSkyCourse       float64
+	proprietary         []string
+	faultPRN            int
+	endOfRoute          string
+	recent              []string // ring buffer of transmitted sentences
+	recentNext          int
+	positionPrecision   int
+	lastTransmit        time.Time
+	lastError           error
+	deviation           []deviationPoint // compass deviation table, sorted by heading
+	turnRadius          float64          // radius of a fly-by turn in progress, NM
+	courseHold          bool             // sail the initial course rather than steer for the target
+	holdDistance        float64          // closest approach to the target while holding course, NM
+	altitudeUnit        string
+	clockOffset         time.Duration // GPS time ahead of system time
+	clockDriftPPM       float64       // rate the offset grows, parts per million
+	clockSetAt          time.Time     // when the offset was set, the origin of the drift
+	arrivalRadius       float64       // distance at which a waypoint counts as reached, NM
+	arrivalHysteresis   float64       // distance beyond the arrival radius before a waypoint can be reached again, NM
+	arrivalLatch        int           // waypoint whose arrival has fired, -1 when none
+	fixCache            fixCache      // last GGA and RMC, reused while nothing changes
+	routeAtStart        bool          // broadcast the route as WPL when starting
+	routeInterval       time.Duration // period of route broadcasts, 0 for none
+	routeQueue          []string      // route sentences waiting to be paced out
+	lastRouteBroadcast  time.Time
+	blankStoppedCourse  bool // leave course empty while stopped
+	fixChanges          []fixChange
+	strictASCII         bool      // reject rather than strip invalid characters in user text
+	lowercaseChecksum   bool      // write checksums in lowercase hex, as some legacy devices do
+	originalRoute       *RTZRoute // route as loaded, before densifying
+	fullRoute           *RTZRoute // whole route while an active range is followed
+	rangeStart          int       // index in the whole route of the active range's first waypoint
+	sourceIndex         []int     // for each densified waypoint, the original waypoint ending its leg
+	jumpMode            string    // how manual waypoint changes move the vessel
+	talkerIDs           []string  // talkers each GNSS sentence is sent under
+	physicsDisabled     bool      // state is driven externally through SetState
+	stopOnNoListener    bool      // stop rather than retry when the destination refuses datagrams
+	nmeaVersion         string
+	steering            SteeringDiagnostics // from the last position update
+	autoSwapLatLon      bool                // correct swapped coordinates when loading RTZ
+	speedRamp           time.Duration       // time to accelerate from rest at start
+	rampTarget          float64             // speed being ramped up to; 0 when not ramping
+	engine              *engineModel        // drives RPM output; nil disables it
+	gnssSystems         []int               // systems sharing the sky, for per-system GSA; empty for GPS only
+	transmitMode        string              // TransmitPeriodic or TransmitOnChange
+	heartbeat           time.Duration       // longest silence per sentence in change-driven mode
+	lastSent            map[string]sentRecord
+	decimalDegreesDebug bool           // send $PSIMDD with the position in decimal degrees
+	fixStatusMap        fixStatusTable // RMC/GLL status by fix quality
+	skyEvolution        bool           // move satellites across the sky over time
+	simulationMarker    bool           // send $PSIMU marking the feed as simulated
+	holdPosition        bool           // keep the vessel in place while time advances
+	holdReportsSpeed    bool           // report the commanded speed rather than zero while held
+	routeComplete       bool           // the final waypoint has been reached
+	astern              bool           // moving stern first, so the heading is opposite the course
+	endExtension        float64        // how far to continue past the final waypoint, NM; 0 for no limit
+	extensionLeft       float64        // distance left to run past the final waypoint, NM
+	odometer            float64        // distance run since the last Start, Stop or route load, NM
+	odometerAstern      bool           // count distance run astern on the odometer
+	dwellLeft           time.Duration  // time left holding at a waypoint
+	dwellSpeed          float64        // speed to resume after dwelling
+	dwells              dwellTable     // time to hold at each waypoint of the whole route, by index
+	aisStations         []aisStation   // fixed AIS stations reported with the vessel
+	lastAISReport       time.Time
+	lengthMode          string               // handling of sentences over the length limit
+	lengthReport        SentenceLengthReport // over-length sentences seen
+	sentenceTimeSkew    bool                 // stamp each sentence of a cycle slightly later
+	skyEpoch            time.Time            // GPS time the evolving sky starts from
 }
 
+// End-of-route behaviors
+const (
+	EndOfRouteStop     = "stop"     // stop the vessel at the final waypoint
+	EndOfRouteContinue = "continue" // keep the last speed and course
+	EndOfRouteLoop     = "loop"     // sail the route again from the first waypoint
+)
+
 // SimulatorConfig holds configuration for the simulator
 type SimulatorConfig struct {
-	MulticastIP  string
-	Port         int
-	TransmitRate time.Duration // how often to send NMEA sentences
-	MagneticVar  float64       // magnetic variation for the area
+	MulticastIP              string
+	Port                     int
+	SocketPath               string        // send over the Unix domain socket at this path instead of UDP
+	TransmitRate             time.Duration // how often to send NMEA sentences; must be positive, defaults to 1s
+	MagneticVar              float64       // magnetic variation for the area
+	LineTerminator           string        // sentence terminator: "\r\n" (default), "\n" or "\r"
+	AcquisitionDelay         time.Duration // time after Start before a valid fix is reported
+	BatchDatagram            bool          // send each cycle's sentences in a single datagram
+	PositionPrecision        int           // decimal places of minutes in positions (3-6), defaults to 4
+	DisableMulticastLoopback bool          // keep multicast sentences from listeners on this host, which receive them by default
+	AltitudeUnit             string        // GGA altitude unit: AltitudeMeters (default) or AltitudeFeet
+	RouteAtStart             bool          // transmit the route as WPL sentences when starting
+	RouteInterval            time.Duration // retransmit the route this often; 0 disables
+	BlankCourseWhenStopped   bool          // leave course empty in RMC and VTG while stopped
+	StrictASCII              bool          // reject user sentence text with non-ASCII or reserved characters instead of stripping them
+	LowercaseChecksum        bool          // write checksums as lowercase hex, e.g. *6f, instead of uppercase
+	TalkerIDs                []string      // talker IDs each GNSS sentence is sent under, defaults to GP
+	StopOnNoListener         bool          // stop transmitting when nothing listens on the destination port; retries by default
+	NMEAVersion              string        // sentence layout: NMEAVersion21 or NMEAVersion23 (default)
+	AutoSwapLatLon           bool          // correct RTZ waypoints whose latitude and longitude appear swapped
+	SpeedRamp                time.Duration // accelerate from rest to the set speed over this long at start
+	TransmitMode             string        // TransmitPeriodic (default) or TransmitOnChange
+	Heartbeat                time.Duration // longest an unchanged sentence goes unsent in change-driven mode, defaults to DefaultHeartbeat
+	StartProfile             string        // StartCold, StartWarm or StartHot acquisition, overriding AcquisitionDelay; empty for none
+}
+
+// GGA altitude units
+const (
+	AltitudeMeters = "m"
+	AltitudeFeet   = "ft"
+)
+
+// feetPerMeter converts altitudes stored in meters to feet
+const feetPerMeter = 3.28084
+
+// ValidateAltitudeUnit checks that unit is a supported GGA altitude unit
+func ValidateAltitudeUnit(unit string) error {
+	switch unit {
+	case AltitudeMeters, AltitudeFeet:
+		return nil
+	}
+	return fmt.Errorf("invalid altitude unit %q: use %q or %q", unit, AltitudeMeters, AltitudeFeet)
+}
+
+// Position precision limits, in decimal places of minutes
+const (
+	MinPositionPrecision = 3
+	MaxPositionPrecision = 6
+)
+
+// ValidateLineTerminator checks that terminator is an accepted sentence terminator
+func ValidateLineTerminator(terminator string) error {
+	switch terminator {
+	case "\r\n", "\n", "\r":
+		return nil
+	}
+	return fmt.Errorf("invalid line terminator %q", terminator)
 }
 
 // NewSimulator creates a new NMEA simulator
 func NewSimulator(config SimulatorConfig) (*Simulator, error) {
+	if config.SocketPath != "" {
+		transport, err := NewUnixTransport(config.SocketPath)
+		if err != nil {
+			return nil, err
+		}
+		return NewSimulatorWithTransport(config, transport)
+	}
+
 	// Default to localhost if no multicast IP specified
 	if config.MulticastIP == "" {
 		config.MulticastIP = "127.0.0.1"
 	}
 
-	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", config.MulticastIP, config.Port))
+	transport, err := NewUDPTransport(config.MulticastIP, config.Port)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve multicast address: %w", err)
+		return nil, err
+	}
+
+	if err := transport.SetMulticastLoopback(!config.DisableMulticastLoopback); err != nil {
+		transport.Close()
+		return nil, err
 	}
 
-	conn, err := net.DialUDP("udp", nil, addr)
+	s, err := NewSimulatorWithTransport(config, transport)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create UDP connection: %w", err)
+		transport.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// NewSimulatorWithTransport creates a simulator that sends its sentences over
+// transport instead of UDP. The address fields of config are ignored, and the
+// simulator takes ownership of the transport, closing it on Close
+func NewSimulatorWithTransport(config SimulatorConfig, transport Transport) (*Simulator, error) {
+	if transport == nil {
+		return nil, fmt.Errorf("transport is required")
+	}
+
+	// A non-positive rate would make the transmit ticker panic
+	if config.TransmitRate <= 0 {
+		config.TransmitRate = 1 * time.Second
+	}
+
+	if config.AcquisitionDelay < 0 {
+		return nil, fmt.Errorf("acquisition delay cannot be negative")
+	}
+
+	if config.PositionPrecision == 0 {
+		config.PositionPrecision = 4
+	}
+	if config.PositionPrecision < MinPositionPrecision || config.PositionPrecision > MaxPositionPrecision {
+		return nil, fmt.Errorf("position precision must be between %d and %d", MinPositionPrecision, MaxPositionPrecision)
+	}
+
+	if config.RouteInterval < 0 {
+		return nil, fmt.Errorf("route broadcast interval cannot be negative")
+	}
+
+	if len(config.TalkerIDs) == 0 {
+		config.TalkerIDs = []string{"GP"}
+	}
+	if err := ValidateTalkerIDs(config.TalkerIDs); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateSpeedRamp(config.SpeedRamp); err != nil {
+		return nil, err
+	}
+
+	if config.TransmitMode == "" {
+		config.TransmitMode = TransmitPeriodic
+	}
+	if err := ValidateTransmitMode(config.TransmitMode); err != nil {
+		return nil, err
+	}
+
+	if config.Heartbeat == 0 {
+		config.Heartbeat = DefaultHeartbeat
+	}
+	if config.Heartbeat < 0 {
+		return nil, fmt.Errorf("heartbeat must be positive")
+	}
+
+	if err := ValidateStartProfile(config.StartProfile); err != nil {
+		return nil, err
+	}
+	profile := startProfiles[config.StartProfile]
+	if config.StartProfile != "" {
+		config.AcquisitionDelay = profile.acquisition
+	}
+
+	if config.NMEAVersion == "" {
+		config.NMEAVersion = NMEAVersion23
+	}
+	if err := ValidateNMEAVersion(config.NMEAVersion); err != nil {
+		return nil, err
+	}
+
+	if config.AltitudeUnit == "" {
+		config.AltitudeUnit = AltitudeMeters
+	}
+	if err := ValidateAltitudeUnit(config.AltitudeUnit); err != nil {
+		return nil, err
+	}
+
+	if config.LineTerminator == "" {
+		config.LineTerminator = "\r\n"
+	}
+	if err := ValidateLineTerminator(config.LineTerminator); err != nil {
+		return nil, err
 	}
 
 	return &Simulator{
-		multicastAddr: addr,
-		conn:          conn,
-		transmitRate:  config.TransmitRate,
-		stopChan:      make(chan struct{}),
+		transport:          transport,
+		transmitRate:       config.TransmitRate,
+		lineTerminator:     config.LineTerminator,
+		acquisition:        config.AcquisitionDelay,
+		batchDatagram:      config.BatchDatagram,
+		positionPrecision:  config.PositionPrecision,
+		altitudeUnit:       config.AltitudeUnit,
+		arrivalRadius:      DefaultArrivalRadius,
+		arrivalHysteresis:  DefaultArrivalHysteresis,
+		arrivalLatch:       -1,
+		lengthMode:         SentenceLengthWarn,
+		odometerAstern:     true,
+		routeAtStart:       config.RouteAtStart,
+		routeInterval:      config.RouteInterval,
+		blankStoppedCourse: config.BlankCourseWhenStopped,
+		strictASCII:        config.StrictASCII,
+		lowercaseChecksum:  config.LowercaseChecksum,
+		stopChan:           make(chan struct{}),
+		rng:                rand.New(rand.NewSource(time.Now().UnixNano())),
+		sky:                skyForCount(8),
+		endOfRoute:         EndOfRouteStop,
+		jumpMode:           WaypointJumpTeleport,
+		talkerIDs:          append([]string(nil), config.TalkerIDs...),
+		stopOnNoListener:   config.StopOnNoListener,
+		nmeaVersion:        config.NMEAVersion,
+		autoSwapLatLon:     config.AutoSwapLatLon,
+		transmitMode:       config.TransmitMode,
+		heartbeat:          config.Heartbeat,
+		lastSent:           make(map[string]sentRecord),
+		fixStatusMap:       defaultFixStatus,
+		speedRamp:          config.SpeedRamp,
+		startProfile:       profile,
 		state: NavigationState{
 			MagneticVar: config.MagneticVar,
 			FixQuality:  1,
@@ -136,50 +478,450 @@ func (s *Simulator) SetPosition(lat, lon, speed, course float64) {
 		Timestamp: time.Now().UTC(),
 	}
 	s.state.Speed = speed
-	s.state.Course = course
+	s.state.Course = s.normalizeCourse(course)
+	s.astern = false
 }
 
-// UpdateSpeed updates the current speed
+// UpdateSpeed updates the current speed. A negative speed moves the vessel
+// astern, as SetAstern
 func (s *Simulator) UpdateSpeed(speed float64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.state.Speed = speed
+	s.setAstern(speed < 0)
+	s.dwellLeft = 0 // an explicit speed ends any dwell
+	s.state.Speed = math.Abs(speed)
+	s.rampTarget = 0 // an explicit speed ends any warm-up ramp
 }
 
 // UpdateCourse updates the current course
 func (s *Simulator) UpdateCourse(course float64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.state.Course = course
+	s.state.Course = s.normalizeCourse(course)
 }
 
-// LoadRTZRoute loads a route from RTZ XML data
-func (s *Simulator) LoadRTZRoute(rtzData []byte, initialSpeed float64) error {
+// SetInitialCourse sets the course a route starts on. The course is held
+// rather than steered until the vessel reaches its first target waypoint or
+// stops closing on it, then the vessel turns onto the route as normal. Loading
+// a route or changing the target waypoint releases the hold
+func (s *Simulator) SetInitialCourse(course float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Course = s.normalizeCourse(course)
+	s.courseHold = true
+	s.holdDistance = math.Inf(1)
+}
+
+// utf8BOM is the byte order mark some editors and ECDIS exporters write at
+// the start of UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// TrimRTZPrefix strips byte order marks and whitespace ahead of the XML
+// declaration, which some exporters write and which a declaration must not
+// be preceded by
+func TrimRTZPrefix(data []byte) []byte {
+	for {
+		trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, utf8BOM), " \t\r\n")
+		if len(trimmed) == len(data) {
+			return trimmed
+		}
+		data = trimmed
+	}
+}
+
+// parseRTZRoute parses RTZ XML data into a route
+func parseRTZRoute(rtzData []byte) (*RTZRoute, error) {
 	var rtz rtzRoute
-	if err := xml.Unmarshal(rtzData, &rtz); err != nil {
-		return fmt.Errorf("failed to parse RTZ data: %w", err)
+	if err := xml.Unmarshal(TrimRTZPrefix(rtzData), &rtz); err != nil {
+		return nil, fmt.Errorf("failed to parse RTZ data: %w", err)
 	}
 
 	if len(rtz.Waypoints) == 0 {
-		return fmt.Errorf("no waypoints found in RTZ file")
+		return nil, fmt.Errorf("no waypoints found in RTZ file")
 	}
 
 	route := &RTZRoute{
-		Waypoints: make([]Waypoint, len(rtz.Waypoints)),
+		Name:       rtz.RouteInfo.RouteName,
+		VesselName: rtz.RouteInfo.VesselName,
+		VesselIMO:  rtz.RouteInfo.VesselIMO,
+		Version:    rtz.Version,
+		Waypoints:  make([]Waypoint, len(rtz.Waypoints)),
 	}
 
 	for i, wp := range rtz.Waypoints {
+		// RTZ gives a turn radius for waypoints the vessel cuts the corner on
+		mode := SteeringFlyOver
+		if wp.Radius > 0 {
+			mode = SteeringFlyBy
+		}
+
 		route.Waypoints[i] = Waypoint{
-			ID:        wp.ID,
-			Latitude:  wp.Position.Latitude,
-			Longitude: wp.Position.Longitude,
+			ID:           wp.ID,
+			Name:         wp.Name,
+			Latitude:     wp.Position.Latitude,
+			Longitude:    wp.Position.Longitude,
+			SteeringMode: mode,
+			Radius:       wp.Radius,
+		}
+	}
+
+	return route, nil
+}
+
+// CoordinatesSwapped reports whether a waypoint position looks like latitude
+// and longitude were entered the wrong way round: the latitude is impossible
+// but would be valid as a longitude, and the longitude would be valid as a
+// latitude
+func CoordinatesSwapped(lat, lon float64) bool {
+	return (lat < -90 || lat > 90) && lat >= -180 && lat <= 180 && lon >= -90 && lon <= 90
+}
+
+// parseRoute parses RTZ XML data, swapping reversed coordinates back when
+// auto-swap is enabled
+func (s *Simulator) parseRoute(rtzData []byte) (*RTZRoute, error) {
+	route, err := parseRTZRoute(rtzData)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	swap := s.autoSwapLatLon
+	s.mu.RUnlock()
+
+	if swap {
+		for i := range route.Waypoints {
+			wp := &route.Waypoints[i]
+			if CoordinatesSwapped(wp.Latitude, wp.Longitude) {
+				wp.Latitude, wp.Longitude = wp.Longitude, wp.Latitude
+			}
 		}
 	}
 
+	return route, nil
+}
+
+// SetAutoSwapLatLon sets whether RTZ waypoints whose latitude and longitude
+// appear swapped are corrected when a route is loaded
+func (s *Simulator) SetAutoSwapLatLon(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.autoSwapLatLon = enabled
+}
+
+// SetLineTerminator sets the terminator appended to each transmitted sentence
+func (s *Simulator) SetLineTerminator(terminator string) error {
+	if err := ValidateLineTerminator(terminator); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lineTerminator = terminator
+	return nil
+}
+
+// SetMultipathMode enables occasional large position jumps in the transmitted
+// fix, mimicking GPS multipath. The true simulated position is unaffected, so
+// each jump snaps back on the next transmission
+func (s *Simulator) SetMultipathMode(enabled bool, jumpProbability float64) error {
+	if jumpProbability < 0 || jumpProbability > 1 {
+		return fmt.Errorf("jump probability must be between 0 and 1")
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.multipath = enabled
+	s.multipathProb = jumpProbability
+	return nil
+}
+
+// SetAcquisitionDelay sets how many seconds after Start the simulator reports
+// no fix, ramping the satellite count up before the fix becomes valid
+func (s *Simulator) SetAcquisitionDelay(seconds int) error {
+	if seconds < 0 {
+		return fmt.Errorf("acquisition delay cannot be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acquisition = time.Duration(seconds) * time.Second
+	return nil
+}
+
+// SetBatchDatagram sets whether all sentences of a transmit cycle are sent in a
+// single datagram instead of one datagram per sentence. Each sentence keeps its
+// terminator, so receivers that split on line endings still work
+func (s *Simulator) SetBatchDatagram(batch bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batchDatagram = batch
+}
+
+// SetEndOfRouteBehavior sets what happens on reaching the final waypoint:
+// EndOfRouteStop (default), EndOfRouteContinue or EndOfRouteLoop
+func (s *Simulator) SetEndOfRouteBehavior(mode string) error {
+	switch mode {
+	case EndOfRouteStop, EndOfRouteContinue, EndOfRouteLoop:
+	default:
+		return fmt.Errorf("invalid end-of-route behavior %q", mode)
+	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endOfRoute = mode
+	return nil
+}
+
+// SetRouteEndExtension makes the vessel carry on past the final waypoint on
+// its last course for continueNM nautical miles and then stop, or
+// indefinitely when continueNM is 0. It selects EndOfRouteContinue
+func (s *Simulator) SetRouteEndExtension(continueNM float64) error {
+	if continueNM < 0 || math.IsNaN(continueNM) || math.IsInf(continueNM, 0) {
+		return fmt.Errorf("route end extension must be zero or more nautical miles")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endOfRoute = EndOfRouteContinue
+	s.endExtension = continueNM
+	return nil
+}
+
+// SetFixQuality sets the GGA fix quality indicator (0-8)
+func (s *Simulator) SetFixQuality(quality int) error {
+	if quality < 0 || quality > 8 {
+		return fmt.Errorf("fix quality must be between 0 and 8")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.FixQuality = quality
+	return nil
+}
+
+// fixChange is a fix quality change scheduled for a moment in GPS time
+type fixChange struct {
+	At      time.Time
+	Quality int
+}
+
+// ScheduleFixQualityChange switches the fix quality at the given GPS time, to
+// reproduce transitions such as entering DGPS coverage mid-run. GPS time is
+// the system time unless a clock offset is set; a time already passed takes
+// effect on the next transmission
+func (s *Simulator) ScheduleFixQualityChange(at time.Time, quality int) error {
+	if quality < 0 || quality > 8 {
+		return fmt.Errorf("fix quality must be between 0 and 8")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fixChanges = append(s.fixChanges, fixChange{At: at, Quality: quality})
+	return nil
+}
+
+// applyFixChanges applies scheduled fix quality changes that are due, in
+// time order. The caller must hold the write lock
+func (s *Simulator) applyFixChanges(now time.Time) {
+	pending := s.fixChanges[:0]
+	var due []fixChange
+	for _, change := range s.fixChanges {
+		if now.Before(change.At) {
+			pending = append(pending, change)
+		} else {
+			due = append(due, change)
+		}
+	}
+	s.fixChanges = pending
+
+	sort.Slice(due, func(i, j int) bool { return due[i].At.Before(due[j].At) })
+	for _, change := range due {
+		s.state.FixQuality = change.Quality
+	}
+}
+
+// SetDGPSCorrection sets the differential reference station ID and the age of
+// corrections in seconds, reported in GGA for differential fixes
+func (s *Simulator) SetDGPSCorrection(stationID int, age float64) error {
+	if stationID < 0 || stationID > 1023 {
+		return fmt.Errorf("DGPS station ID must be between 0 and 1023")
+	}
+	if age < 0 {
+		return fmt.Errorf("DGPS age cannot be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.DGPSStationID = stationID
+	s.state.DGPSAge = age
+	return nil
+}
+
+// SetPositionPrecision sets the number of decimal places of minutes in
+// transmitted latitudes and longitudes
+func (s *Simulator) SetPositionPrecision(decimals int) error {
+	if decimals < MinPositionPrecision || decimals > MaxPositionPrecision {
+		return fmt.Errorf("position precision must be between %d and %d", MinPositionPrecision, MaxPositionPrecision)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.positionPrecision = decimals
+	return nil
+}
+
+// SetAltitudeUnit sets the unit of the GGA altitude and geoidal separation.
+// Altitude is kept in meters and converted on output
+func (s *Simulator) SetAltitudeUnit(unit string) error {
+	if err := ValidateAltitudeUnit(unit); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.altitudeUnit = unit
+	return nil
+}
+
+// Waypoint arrival radius limits and units
+const (
+	DefaultArrivalRadius     = 0.02 // NM; reduced from 0.1 for better accuracy
+	MaxArrivalRadius         = 5.0  // NM
+	DefaultArrivalHysteresis = 0.01 // NM
+
+	DistanceNauticalMiles = "nm"
+	DistanceMeters        = "m"
+
+	metersPerNauticalMile = 1852.0
+)
+
+// SetArrivalRadius sets how close the vessel must come to a waypoint for it to
+// count as reached, in nautical miles ("nm") or meters ("m")
+func (s *Simulator) SetArrivalRadius(value float64, unit string) error {
+	radius := value
+	switch unit {
+	case DistanceNauticalMiles:
+	case DistanceMeters:
+		radius = value / metersPerNauticalMile
+	default:
+		return fmt.Errorf("invalid distance unit %q: use %q or %q", unit, DistanceNauticalMiles, DistanceMeters)
+	}
+
+	if radius <= 0 || radius > MaxArrivalRadius {
+		return fmt.Errorf("arrival radius must be greater than 0 and at most %.0f NM", MaxArrivalRadius)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.arrivalRadius = radius
+	return nil
+}
+
+// SetArrivalHysteresis sets how far beyond the arrival radius the vessel must
+// move from a reached waypoint before any waypoint can be reached again, in
+// nautical miles ("nm") or meters ("m"). This stops a vessel lingering at the
+// arrival circle from advancing more than once
+func (s *Simulator) SetArrivalHysteresis(value float64, unit string) error {
+	hysteresis := value
+	switch unit {
+	case DistanceNauticalMiles:
+	case DistanceMeters:
+		hysteresis = value / metersPerNauticalMile
+	default:
+		return fmt.Errorf("invalid distance unit %q: use %q or %q", unit, DistanceNauticalMiles, DistanceMeters)
+	}
+
+	if hysteresis < 0 || hysteresis > MaxArrivalRadius {
+		return fmt.Errorf("arrival hysteresis must be between 0 and %.0f NM", MaxArrivalRadius)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.arrivalHysteresis = hysteresis
+	return nil
+}
+
+// MaxClockDriftPPM is the largest simulated receiver clock drift, in parts per million
+const MaxClockDriftPPM = 1000.0
+
+// SetClockOffset offsets transmitted GPS time from the system clock by offset,
+// growing by driftPPM parts per million from now on, to exercise consumers
+// that compare GPS time against system time
+func (s *Simulator) SetClockOffset(offset time.Duration, driftPPM float64) error {
+	if math.Abs(driftPPM) > MaxClockDriftPPM {
+		return fmt.Errorf("clock drift must be between -%.0f and %.0f ppm", MaxClockDriftPPM, MaxClockDriftPPM)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clockOffset = offset
+	s.clockDriftPPM = driftPPM
+	s.clockSetAt = time.Now()
+	return nil
+}
+
+// gpsTime returns the GPS time reported for system time t, applying the
+// simulated clock offset and drift
+func (s *Simulator) gpsTime(t time.Time) time.Time {
+	if s.clockOffset == 0 && s.clockDriftPPM == 0 {
+		return t
+	}
+
+	drift := time.Duration(float64(t.Sub(s.clockSetAt)) * s.clockDriftPPM / 1e6)
+	return t.Add(s.clockOffset + drift)
+}
+
+// GPSTime returns the current GPS time as transmitted, including any simulated
+// clock offset and drift
+func (s *Simulator) GPSTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.gpsTime(time.Now().UTC())
+}
+
+// SetCurrent sets the water current acting on the vessel. The vessel keeps its
+// commanded speed and course over ground, so the current only changes the
+// water-referenced speeds
+func (s *Simulator) SetCurrent(set, drift float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.CurrentSet = set
+	s.state.CurrentDrift = drift
+}
+
+// LoadRTZRoute loads a route from RTZ XML data
+func (s *Simulator) LoadRTZRoute(rtzData []byte, initialSpeed float64) error {
+	route, err := s.parseRoute(rtzData)
+	if err != nil {
+		return err
+	}
+
+	return s.SetRoute(route, initialSpeed)
+}
+
+// SetRoute sets the route to follow, placing the vessel on the first waypoint
+func (s *Simulator) SetRoute(route *RTZRoute, initialSpeed float64) error {
+	if route == nil || len(route.Waypoints) == 0 {
+		return fmt.Errorf("route has no waypoints: %w", ErrNoRoute)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	route = s.removeCoincidentWaypoints(route)
 	s.route = route
+	s.originalRoute = nil
+	s.sourceIndex = nil
+	s.fullRoute = nil
+	s.astern = false
+	s.odometer = 0
+	s.routeComplete = false
+	s.arrivalLatch = -1
+	s.extensionLeft = 0
+	s.endDwell()
+	s.dwells = nil
+	s.courseHold = false
+	s.rangeStart = 0
 	s.autoNavigate = true
 
 	// Set initial position to first waypoint
@@ -200,29 +942,176 @@ func (s *Simulator) LoadRTZRoute(rtzData []byte, initialSpeed float64) error {
 		// Single waypoint route - already at destination
 		s.currentWaypoint = 0
 		s.autoNavigate = false
+		s.routeComplete = true
+	}
+
+	return nil
+}
+
+// LoadRTZRouteFromPosition loads a route from RTZ XML data but keeps the current
+// position, so the vessel first transits to waypoint 0 before following the route
+func (s *Simulator) LoadRTZRouteFromPosition(rtzData []byte, initialSpeed float64) error {
+	route, err := s.parseRoute(rtzData)
+	if err != nil {
+		return err
+	}
+
+	return s.SetRouteFromPosition(route, initialSpeed)
+}
+
+// SetRouteFromPosition sets the route to follow while keeping the current
+// position, targeting waypoint 0 first
+func (s *Simulator) SetRouteFromPosition(route *RTZRoute, initialSpeed float64) error {
+	if route == nil || len(route.Waypoints) == 0 {
+		return fmt.Errorf("route has no waypoints: %w", ErrNoRoute)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	route = s.removeCoincidentWaypoints(route)
+	s.route = route
+	s.originalRoute = nil
+	s.sourceIndex = nil
+	s.fullRoute = nil
+	s.astern = false
+	s.odometer = 0
+	s.routeComplete = false
+	s.arrivalLatch = -1
+	s.extensionLeft = 0
+	s.endDwell()
+	s.dwells = nil
+	s.courseHold = false
+	s.rangeStart = 0
+	s.autoNavigate = true
+	s.state.Speed = initialSpeed
+	s.state.Position.Timestamp = time.Now().UTC()
+
+	// Target the first waypoint; the approach leg has no previous waypoint,
+	// so cross-track correction only starts once the route proper begins
+	firstWP := route.Waypoints[0]
+	s.currentWaypoint = 0
+	s.state.Course = s.calculateCourse(s.state.Position.Latitude, s.state.Position.Longitude,
+		firstWP.Latitude, firstWP.Longitude)
+
+	return nil
+}
+
+// OffsetRoute shifts every waypoint and the vessel by dLat and dLon degrees,
+// relocating the route to another area while keeping its shape in degrees.
+// Longitudes wrap at the antimeridian; the route must not be pushed past a pole
+func (s *Simulator) OffsetRoute(dLat, dLon float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.route == nil {
+		return ErrNoRoute
+	}
+
+	shifted, err := s.offsetWaypoints(s.route, dLat, dLon)
+	if err != nil {
+		return err
+	}
+
+	// A densified route keeps its original, and a range its whole route,
+	// which move with it
+	var original, full *RTZRoute
+	if s.originalRoute != nil {
+		if original, err = s.offsetWaypoints(s.originalRoute, dLat, dLon); err != nil {
+			return err
+		}
+	}
+	if s.fullRoute != nil {
+		if full, err = s.offsetWaypoints(s.fullRoute, dLat, dLon); err != nil {
+			return err
+		}
+	}
+
+	lat := s.state.Position.Latitude + dLat
+	if lat < -90 || lat > 90 {
+		return fmt.Errorf("offset moves the vessel beyond the pole")
+	}
+
+	s.route = shifted
+	s.originalRoute = original
+	s.fullRoute = full
+	s.state.Position.Latitude = lat
+	s.state.Position.Longitude = s.normalizeLongitude(s.state.Position.Longitude + dLon)
+	s.state.Position.Timestamp = time.Now().UTC()
+
+	// Bearings change with latitude, so aim at the target afresh
+	if s.autoNavigate && s.currentWaypoint < len(s.route.Waypoints) {
+		targetWP := s.route.Waypoints[s.currentWaypoint]
+		s.state.Course = s.calculateCourse(
+			s.state.Position.Latitude, s.state.Position.Longitude,
+			targetWP.Latitude, targetWP.Longitude,
+		)
 	}
 
 	return nil
 }
 
+// offsetWaypoints returns a copy of route with every waypoint shifted by dLat
+// and dLon degrees
+func (s *Simulator) offsetWaypoints(route *RTZRoute, dLat, dLon float64) (*RTZRoute, error) {
+	shifted := route.withWaypoints(make([]Waypoint, len(route.Waypoints)))
+	for i, wp := range route.Waypoints {
+		wp.Latitude += dLat
+		wp.Longitude = s.normalizeLongitude(wp.Longitude + dLon)
+		if wp.Latitude < -90 || wp.Latitude > 90 {
+			return nil, fmt.Errorf("offset moves waypoint %d beyond the pole", i)
+		}
+		shifted.Waypoints[i] = wp
+	}
+	return shifted, nil
+}
+
+// removeCoincidentWaypoints returns a copy of the route without waypoints that
+// share the position of the previous one. Zero-length legs have no defined
+// bearing and would otherwise make the vessel stall or advance erratically
+func (s *Simulator) removeCoincidentWaypoints(route *RTZRoute) *RTZRoute {
+	const coincidentThresholdNM = 0.0001
+
+	cleaned := route.withWaypoints([]Waypoint{route.Waypoints[0]})
+
+	for _, wp := range route.Waypoints[1:] {
+		prev := cleaned.Waypoints[len(cleaned.Waypoints)-1]
+		if s.calculateDistance(prev.Latitude, prev.Longitude, wp.Latitude, wp.Longitude) < coincidentThresholdNM {
+			continue
+		}
+		cleaned.Waypoints = append(cleaned.Waypoints, wp)
+	}
+
+	return cleaned
+}
+
 // Start begins the NMEA transmission
 func (s *Simulator) Start() error {
 	s.mu.Lock()
 	if s.running {
 		s.mu.Unlock()
-		return fmt.Errorf("simulator is already running")
+		return ErrAlreadyRunning
 	}
 	s.running = true
 	s.stopChan = make(chan struct{})
+	s.startTime = time.Now()
+	s.lastTransmit = s.startTime // grace period until the first transmission
+	s.lastRouteBroadcast = s.startTime
+	if s.routeAtStart {
+		s.queueRouteBroadcast()
+	}
+	s.startSpeedRamp()
+	s.odometer = 0
 	s.mu.Unlock()
 
+	s.loops.Add(2)
 	go s.simulationLoop()
 	go s.transmissionLoop()
 
 	return nil
 }
 
-// Stop stops the NMEA transmission
+// Stop stops the NMEA transmission and resets the odometer
 func (s *Simulator) Stop() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -231,16 +1120,42 @@ func (s *Simulator) Stop() {
 		s.running = false
 		close(s.stopChan)
 	}
+	s.odometer = 0
+}
+
+// StopAndFlush stops the NMEA transmission and waits for any in-flight
+// transmission to finish writing, so output isn't cut off mid-cycle. It gives
+// up when ctx is done; Stop remains available for an abrupt stop
+func (s *Simulator) StopAndFlush(ctx context.Context) error {
+	s.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out flushing output: %w", ctx.Err())
+	}
 }
 
-// Close closes the simulator and releases resources
+// Close closes the simulator and releases resources. It waits for the
+// simulation and transmission loops to exit first, so a transmission in
+// progress never writes to a closed transport
 func (s *Simulator) Close() error {
 	s.Stop()
-	return s.conn.Close()
+	s.loops.Wait()
+	return s.transport.Close()
 }
 
 // simulationLoop updates the position based on speed and course
 func (s *Simulator) simulationLoop() {
+	defer s.loops.Done()
+
 	ticker := time.NewTicker(1 * time.Second) // Update position every second
 	defer ticker.Stop()
 
@@ -281,29 +1196,153 @@ func (s *Simulator) calculateCrossTrackError() float64 {
 		targetWP.Latitude, targetWP.Longitude) * math.Pi / 180
 
 	// Cross-track error in nautical miles (positive = right of track)
-	dxt := math.Asin(math.Sin(d13/3440.065)*math.Sin(θ13-θ12)) * 3440.065
+	dxt := math.Asin(s.clamp(math.Sin(d13/3440.065)*math.Sin(θ13-θ12), -1, 1)) * 3440.065
 
 	return dxt
 }
 
+// calculateAlongTrackDistance calculates how far along the current leg the
+// vessel is, measured from the previous waypoint (negative if behind it)
+func (s *Simulator) calculateAlongTrackDistance() float64 {
+	if s.route == nil || s.currentWaypoint == 0 || s.currentWaypoint >= len(s.route.Waypoints) {
+		return 0
+	}
+
+	prevWP := s.route.Waypoints[s.currentWaypoint-1]
+	targetWP := s.route.Waypoints[s.currentWaypoint]
+	currentPos := s.state.Position
+
+	d13 := s.calculateDistance(prevWP.Latitude, prevWP.Longitude,
+		currentPos.Latitude, currentPos.Longitude) / 3440.065
+	dxt := s.calculateCrossTrackError() / 3440.065
+
+	dat := math.Acos(s.clamp(math.Cos(d13)/math.Cos(dxt), -1, 1)) * 3440.065
+
+	// Behind the previous waypoint if the bearing to the vessel points away from the leg
+	θ13 := s.calculateCourse(prevWP.Latitude, prevWP.Longitude, currentPos.Latitude, currentPos.Longitude)
+	θ12 := s.calculateCourse(prevWP.Latitude, prevWP.Longitude, targetWP.Latitude, targetWP.Longitude)
+	if s.courseDifference(θ13, θ12) > 90 {
+		dat = -dat
+	}
+
+	return dat
+}
+
 // updatePosition calculates new position based on current speed and course
 func (s *Simulator) updatePosition() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.advance(time.Second, time.Now().UTC())
+}
+
+// HoldPosition sets whether the vessel is held in place. Unlike pausing,
+// sentences keep being sent with advancing timestamps, like a receiver with a
+// valid fix sitting still. The commanded speed and course are kept for release
+func (s *Simulator) HoldPosition(hold bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holdPosition = hold
+}
+
+// SetHoldReportsSpeed sets whether a held vessel reports its commanded speed
+// over ground rather than zero
+func (s *Simulator) SetHoldReportsSpeed(report bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holdReportsSpeed = report
+}
+
+// Step advances the simulation by elapsed without waiting on the real-time
+// loop, so route following can be run faster than real time and inspected
+// deterministically. Long steps are taken a second at a time, as the real-time
+// loop does, and the position timestamp advances by elapsed
+func (s *Simulator) Step(elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for elapsed > 0 {
+		dt := min(elapsed, time.Second)
+		timestamp := s.state.Position.Timestamp.Add(dt)
+		s.advance(dt, timestamp)
+		s.state.Position.Timestamp = timestamp
+		elapsed -= dt
+	}
+}
+
+// advance moves the simulation on by dt, stamping a new position with
+// timestamp. The caller must hold the write lock
+func (s *Simulator) advance(dt time.Duration, timestamp time.Time) {
+	if s.physicsDisabled {
+		return
+	}
+
+	// A held vessel stays put but its fix stays live
+	if s.holdPosition {
+		s.state.Position.Timestamp = timestamp
+		return
+	}
+
+	s.stepSpeedRamp(dt)
+
+	// Dwelling at a waypoint: hold position while time runs on
+	if s.stepDwell(dt) {
+		s.state.Position.Timestamp = timestamp
+		return
+	}
 
 	if s.state.Speed <= 0 {
 		return
 	}
 
-	// Time elapsed since last update (1 second)
-	timeElapsed := 1.0 / 3600.0 // 1 second in hours
+	s.steering = SteeringDiagnostics{}
 
 	// Distance traveled in nautical miles
-	distanceNM := s.state.Speed * timeElapsed
+	distanceNM := s.state.Speed * dt.Hours()
+
+	// Keep an initial course while it still closes on the target
+	if s.courseHold && s.route != nil && s.currentWaypoint < len(s.route.Waypoints) {
+		targetWP := s.route.Waypoints[s.currentWaypoint]
+		distance := s.calculateDistance(
+			s.state.Position.Latitude, s.state.Position.Longitude,
+			targetWP.Latitude, targetWP.Longitude,
+		)
+		if distance < s.holdDistance {
+			s.holdDistance = distance
+		} else {
+			s.courseHold = false
+		}
+	}
+
+	// Aim at the current target each tick so the course doesn't go stale on long
+	// legs, turning no faster than the maximum rate of turn
+	if s.autoNavigate && !s.courseHold && s.route != nil && s.currentWaypoint < len(s.route.Waypoints) {
+		maxTurnRateDegPerSec := 3.0
+
+		// A fly-by turn follows its radius: rate of turn is speed over radius
+		if s.turnRadius > 0 {
+			radiusRate := s.state.Speed / s.turnRadius * 180 / math.Pi / 3600
+			maxTurnRateDegPerSec = math.Min(maxTurnRateDegPerSec, radiusRate)
+		}
+		maxTurn := maxTurnRateDegPerSec * dt.Seconds()
+
+		targetWP := s.route.Waypoints[s.currentWaypoint]
+		desiredCourse := s.calculateCourse(
+			s.state.Position.Latitude, s.state.Position.Longitude,
+			targetWP.Latitude, targetWP.Longitude,
+		)
+		s.state.Course = s.turnToward(s.state.Course, desiredCourse, maxTurn)
+		s.steering.Active = true
+		s.steering.DesiredCourse = desiredCourse
+
+		if s.courseDifference(s.state.Course, desiredCourse) < 1 {
+			s.turnRadius = 0
+		}
+	}
 
-	// Apply cross-track error correction if following a route
+	// Apply cross-track error correction if following a route, except while
+	// turning onto the new leg through a fly-by waypoint
 	courseToUse := s.state.Course
-	if s.autoNavigate && s.route != nil && s.currentWaypoint > 0 {
+	if s.autoNavigate && !s.courseHold && s.route != nil && s.currentWaypoint > 0 && s.turnRadius == 0 {
 		crossTrackError := s.calculateCrossTrackError()
 
 		// Apply proportional correction (maximum 30 degrees correction)
@@ -321,6 +1360,19 @@ func (s *Simulator) updatePosition() {
 		} else if courseToUse >= 360 {
 			courseToUse -= 360
 		}
+
+		s.steering.CrossTrackError = crossTrackError
+		s.steering.Correction = correctionDegrees
+	}
+	s.steering.Heading = courseToUse
+
+	// A limited run past the final waypoint ends exactly at its distance. Only
+	// a completed route is extended, so a run left over when the vessel is sent
+	// back onto the route has no effect
+	extending := s.routeComplete && s.extensionLeft > 0
+	extensionDone := extending && distanceNM >= s.extensionLeft
+	if extensionDone {
+		distanceNM = s.extensionLeft
 	}
 
 	// Calculate new position using the corrected course
@@ -331,9 +1383,29 @@ func (s *Simulator) updatePosition() {
 		distanceNM,
 	)
 
+	// A track passing over a pole leaves it heading the opposite way, which
+	// shows up as the arrival bearing differing from the departure bearing by
+	// more than 90 degrees
+	arrivalCourse := s.calculateCourse(newLat, newLon,
+		s.state.Position.Latitude, s.state.Position.Longitude) + 180
+	if s.courseDifference(arrivalCourse, courseToUse) > 90 {
+		s.state.Course = s.normalizeCourse(s.state.Course + 180)
+	}
+
 	s.state.Position.Latitude = newLat
 	s.state.Position.Longitude = newLon
-	s.state.Position.Timestamp = time.Now().UTC()
+	s.state.Position.Timestamp = timestamp
+
+	if extensionDone {
+		s.extensionLeft = 0
+		s.state.Speed = 0
+	} else if extending {
+		s.extensionLeft -= distanceNM
+	}
+
+	if !s.astern || s.odometerAstern {
+		s.odometer += distanceNM
+	}
 
 	// Check if we're following a route and need to update course
 	if s.autoNavigate && s.route != nil {
@@ -341,6 +1413,22 @@ func (s *Simulator) updatePosition() {
 	}
 }
 
+// GetDistanceTraveled returns the distance run in nautical miles since the
+// simulation was last started, stopped or given a route
+func (s *Simulator) GetDistanceTraveled() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.odometer
+}
+
+// SetOdometerCountsAstern sets whether distance run astern adds to the
+// odometer (the default) or only distance run ahead counts
+func (s *Simulator) SetOdometerCountsAstern(count bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.odometerAstern = count
+}
+
 // calculateNewPosition calculates new lat/lon given current position, course, and distance
 func (s *Simulator) calculateNewPosition(lat, lon, course, distanceNM float64) (float64, float64) {
 	const earthRadiusNM = 3440.065 // Earth radius in nautical miles
@@ -350,8 +1438,9 @@ func (s *Simulator) calculateNewPosition(lat, lon, course, distanceNM float64) (
 	courseRad := course * math.Pi / 180
 	distanceRad := distanceNM / earthRadiusNM
 
-	newLatRad := math.Asin(math.Sin(latRad)*math.Cos(distanceRad) +
-		math.Cos(latRad)*math.Sin(distanceRad)*math.Cos(courseRad))
+	// Clamp to the valid asin domain; rounding near the poles can push it past ±1
+	newLatRad := math.Asin(s.clamp(math.Sin(latRad)*math.Cos(distanceRad)+
+		math.Cos(latRad)*math.Sin(distanceRad)*math.Cos(courseRad), -1, 1))
 
 	newLonRad := lonRad + math.Atan2(
 		math.Sin(courseRad)*math.Sin(distanceRad)*math.Cos(latRad),
@@ -360,36 +1449,76 @@ func (s *Simulator) calculateNewPosition(lat, lon, course, distanceNM float64) (
 	newLat := newLatRad * 180 / math.Pi
 	newLon := newLonRad * 180 / math.Pi
 
-	// Normalize longitude to -180 to 180
-	if newLon > 180 {
-		newLon -= 360
-	} else if newLon < -180 {
-		newLon += 360
+	if math.IsNaN(newLat) || math.IsNaN(newLon) {
+		return lat, lon
 	}
 
-	return newLat, newLon
+	return newLat, s.normalizeLongitude(newLon)
 }
 
 // calculateCourse calculates the course between two points
 func (s *Simulator) calculateCourse(lat1, lon1, lat2, lon2 float64) float64 {
 	lat1Rad := lat1 * math.Pi / 180
 	lat2Rad := lat2 * math.Pi / 180
-	deltaLonRad := (lon2 - lon1) * math.Pi / 180
+	deltaLonRad := s.longitudeDifference(lon1, lon2) * math.Pi / 180
 
 	y := math.Sin(deltaLonRad) * math.Cos(lat2Rad)
 	x := math.Cos(lat1Rad)*math.Sin(lat2Rad) - math.Sin(lat1Rad)*math.Cos(lat2Rad)*math.Cos(deltaLonRad)
 
 	courseRad := math.Atan2(y, x)
-	course := courseRad * 180 / math.Pi
+	return s.normalizeCourse(courseRad * 180 / math.Pi)
+}
 
-	// Normalize to 0-360
+// normalizeCourse wraps a course in degrees into the range [0, 360)
+func (s *Simulator) normalizeCourse(course float64) float64 {
+	course = math.Mod(course, 360)
 	if course < 0 {
 		course += 360
 	}
-
+	// A tiny negative course rounds up to exactly 360 when wrapped
+	if course >= 360 {
+		course = 0
+	}
 	return course
 }
 
+// normalizeLongitude wraps a longitude into the range [-180, 180)
+func (s *Simulator) normalizeLongitude(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
+
+// longitudeDifference returns the shortest signed longitude change from lon1
+// to lon2 in degrees, so legs across the antimeridian go the short way
+func (s *Simulator) longitudeDifference(lon1, lon2 float64) float64 {
+	return s.normalizeLongitude(lon2 - lon1)
+}
+
+// turnToward turns from course toward desired by at most maxTurn degrees,
+// taking the shorter direction
+func (s *Simulator) turnToward(course, desired, maxTurn float64) float64 {
+	turn := s.normalizeCourse(desired-course+180) - 180
+	turn = s.clamp(turn, -maxTurn, maxTurn)
+	return s.normalizeCourse(course + turn)
+}
+
+// courseDifference returns the absolute angle between two courses in degrees (0-180)
+func (s *Simulator) courseDifference(a, b float64) float64 {
+	diff := math.Abs(s.normalizeCourse(a) - s.normalizeCourse(b))
+	if diff > 180 {
+		diff = 360 - diff
+	}
+	return diff
+}
+
+// clamp limits v to the range [lo, hi]
+func (s *Simulator) clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
 // calculateDistance calculates distance between two points in nautical miles
 func (s *Simulator) calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
 	const earthRadiusNM = 3440.065
@@ -397,10 +1526,11 @@ func (s *Simulator) calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
 	lat1Rad := lat1 * math.Pi / 180
 	lat2Rad := lat2 * math.Pi / 180
 	deltaLatRad := (lat2 - lat1) * math.Pi / 180
-	deltaLonRad := (lon2 - lon1) * math.Pi / 180
+	deltaLonRad := s.longitudeDifference(lon1, lon2) * math.Pi / 180
 
 	a := math.Sin(deltaLatRad/2)*math.Sin(deltaLatRad/2) +
 		math.Cos(lat1Rad)*math.Cos(lat2Rad)*math.Sin(deltaLonRad/2)*math.Sin(deltaLonRad/2)
+	a = s.clamp(a, 0, 1)
 	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
 
 	return earthRadiusNM * c
@@ -412,6 +1542,20 @@ func (s *Simulator) checkWaypointProximity() {
 		return
 	}
 
+	// Release the arrival latch once the vessel is clear of the reached
+	// waypoint. Until then no waypoint can be reached, so a vessel hovering at
+	// the arrival radius advances only once
+	if s.arrivalLatch >= 0 && s.arrivalLatch < len(s.route.Waypoints) {
+		latched := s.route.Waypoints[s.arrivalLatch]
+		clearance := s.calculateDistance(
+			s.state.Position.Latitude, s.state.Position.Longitude,
+			latched.Latitude, latched.Longitude,
+		)
+		if clearance >= s.arrivalRadius+s.arrivalHysteresis {
+			s.arrivalLatch = -1
+		}
+	}
+
 	// FIX: Check distance to the target waypoint (where we're going)
 	targetWP := s.route.Waypoints[s.currentWaypoint]
 	distance := s.calculateDistance(
@@ -419,31 +1563,92 @@ func (s *Simulator) checkWaypointProximity() {
 		targetWP.Latitude, targetWP.Longitude,
 	)
 
-	// FIX: If within proximity threshold, advance to next waypoint
-	const proximityThresholdNM = 0.02 // Reduced from 0.1 for better accuracy
+	// FIX: If within the arrival radius, advance to next waypoint. Fly-by
+	// waypoints are passed once the turn has to start
+	threshold := math.Max(s.arrivalRadius, s.wheelOverDistance(s.currentWaypoint))
+
+	if distance < threshold && s.arrivalLatch < 0 {
+		s.arrivalLatch = s.currentWaypoint
+		s.courseHold = false
+
+		// Only a waypoint actually reached is dwelt at, not one passed at the
+		// wheel-over point of a fly-by turn
+		if distance < s.arrivalRadius {
+			s.startDwell(s.currentWaypoint)
+		}
 
-	if distance < proximityThresholdNM {
-		// Check if there's a next waypoint to navigate to
+		// Check if there's a next waypoint to navigate to. The vessel then
+		// turns toward it at the rate of turn, on the radius for a fly-by
 		if s.currentWaypoint < len(s.route.Waypoints)-1 {
-			// Advance to next waypoint
 			s.currentWaypoint++
-			nextTargetWP := s.route.Waypoints[s.currentWaypoint]
-
-			// Update course to the new target waypoint
-			s.state.Course = s.calculateCourse(
-				s.state.Position.Latitude, s.state.Position.Longitude,
-				nextTargetWP.Latitude, nextTargetWP.Longitude,
-			)
+			if targetWP.SteeringMode == SteeringFlyBy && targetWP.Radius > 0 {
+				s.turnRadius = targetWP.Radius
+			}
 		} else {
-			// Reached final waypoint - stop auto navigation
-			s.autoNavigate = false
-			s.state.Speed = 0 // Optional: stop the vessel
+			s.finishRoute()
 		}
 	}
 }
 
+// wheelOverDistance returns how far before waypoint index a fly-by turn must
+// start so that the turn on its radius joins the next leg. It is zero for
+// fly-over waypoints and the final waypoint
+func (s *Simulator) wheelOverDistance(index int) float64 {
+	if index >= len(s.route.Waypoints)-1 {
+		return 0
+	}
+
+	wp := s.route.Waypoints[index]
+	if wp.SteeringMode != SteeringFlyBy || wp.Radius <= 0 {
+		return 0
+	}
+
+	// The incoming leg starts at the previous waypoint, or at the vessel
+	// when heading for the first waypoint
+	fromLat, fromLon := s.state.Position.Latitude, s.state.Position.Longitude
+	if index > 0 {
+		prev := s.route.Waypoints[index-1]
+		fromLat, fromLon = prev.Latitude, prev.Longitude
+	}
+	next := s.route.Waypoints[index+1]
+
+	incoming := s.calculateCourse(fromLat, fromLon, wp.Latitude, wp.Longitude)
+	outgoing := s.calculateCourse(wp.Latitude, wp.Longitude, next.Latitude, next.Longitude)
+
+	// Limit near-reversals, where the wheel-over point would run off to infinity
+	turn := math.Min(s.courseDifference(incoming, outgoing), 170)
+
+	return wp.Radius * math.Tan(turn/2*math.Pi/180)
+}
+
+// finishRoute applies the end-of-route behavior on reaching the final waypoint
+func (s *Simulator) finishRoute() {
+	switch s.endOfRoute {
+	case EndOfRouteContinue:
+		// Keep sailing on the last speed and course, for a set distance if limited
+		s.autoNavigate = false
+		s.routeComplete = true
+		s.extensionLeft = s.endExtension
+	case EndOfRouteLoop:
+		// Head back to the first waypoint and sail the route again, turning
+		// toward it at the rate of turn. A route closed on itself has the
+		// first waypoint where the last was just reached, so it isn't latched
+		s.currentWaypoint = 0
+		s.arrivalLatch = -1
+	default:
+		// Reached final waypoint - stop auto navigation and the vessel, with
+		// no speed to resume after any dwell
+		s.autoNavigate = false
+		s.routeComplete = true
+		s.dwellLeft = 0
+		s.state.Speed = 0
+	}
+}
+
 // transmissionLoop sends NMEA sentences at the specified rate
 func (s *Simulator) transmissionLoop() {
+	defer s.loops.Done()
+
 	ticker := time.NewTicker(s.transmitRate)
 	defer ticker.Stop()
 
@@ -457,38 +1662,325 @@ func (s *Simulator) transmissionLoop() {
 	}
 }
 
-// transmitNMEASentences generates and transmits NMEA sentences
-func (s *Simulator) transmitNMEASentences() {
+// transmitNMEASentences generates and transmits NMEA sentences
+func (s *Simulator) transmitNMEASentences() {
+	s.mu.Lock()
+	s.applyFixChanges(s.gpsTime(time.Now().UTC()))
+	state := s.state
+	terminator := s.lineTerminator
+	batch := s.batchDatagram
+	if s.multipath && s.rng.Float64() < s.multipathProb {
+		state.Position = s.multipathJump(state.Position)
+	}
+	state = s.acquiringState(state, time.Since(s.startTime))
+	state.Position.Timestamp = s.gpsTime(state.Position.Timestamp)
+	if s.holdPosition && !s.holdReportsSpeed {
+		state.Speed = 0
+	}
+	sky := s.currentSky()
+	if state.Satellites > len(sky) {
+		state.Satellites = len(sky)
+	}
+
+	// Generate while holding the lock, as the generators read output settings
+	sentences := s.changedSentences(s.cycleSentences(state, sky), time.Now())
+	sentences = append(sentences, s.nextRouteSentences()...)
+	sentences = append(sentences, s.nextAISSentences(state.Position.Timestamp)...)
+	sentences = s.enforceSentenceLength(sentences)
+	s.mu.Unlock()
+
+	s.recordSentences(sentences)
+
+	var err error
+	if batch {
+		var datagram []byte
+		for _, sentence := range sentences {
+			if sentence != "" {
+				datagram = append(datagram, sentence+terminator...)
+			}
+		}
+		if len(datagram) > 0 {
+			err = s.transport.Send(datagram)
+		}
+	} else {
+		for _, sentence := range sentences {
+			if sentence == "" {
+				continue
+			}
+			if writeErr := s.transport.Send([]byte(sentence + terminator)); writeErr != nil && err == nil {
+				err = writeErr
+			}
+		}
+	}
+
+	s.recordTransmit(err)
+}
+
+// cycleSentences generates the sentences sent each transmit cycle. The caller
+// must hold the write lock
+func (s *Simulator) cycleSentences(state NavigationState, sky []satellite) []string {
+	gga, rmc := s.cachedFixSentences(state)
+
+	// With skew, each timed sentence is stamped a little later than the one before
+	timed := 0
+	stamped := func() NavigationState {
+		skewed := state
+		if s.sentenceTimeSkew {
+			skewed.Position.Timestamp = skewed.Position.Timestamp.Add(time.Duration(timed) * sentenceSkew)
+		}
+		timed++
+		return skewed
+	}
+	stamped() // GGA is stamped with the cycle time
+	if s.sentenceTimeSkew {
+		rmc = s.generateRMC(stamped())
+	} else {
+		stamped()
+	}
+
+	sentences := []string{
+		gga,
+		rmc,
+		s.generateGLL(stamped()),
+		s.generateVTG(state),
+	}
+	sentences = append(sentences, s.gsaSentences(state, sky)...)
+	sentences = append(sentences, s.gsvSentences(state, sky)...)
+	sentences = append(sentences, s.generateVBW(state), s.generateGBS(stamped(), s.faultPRN), s.generateHDG(state))
+	if s.engine != nil {
+		sentences = append(sentences, s.generateRPM(state))
+	}
+	if s.simulationMarker {
+		sentences = append(sentences, s.addChecksum(simulationMarker))
+	}
+	if s.decimalDegreesDebug {
+		sentences = append(sentences, s.generateProprietary(decimalDegreesTemplate, stamped()))
+	}
+	for _, template := range s.proprietary {
+		sentences = append(sentences, s.generateProprietary(template, stamped()))
+	}
+	return s.withTalkerIDs(sentences)
+}
+
+// sentenceSkew separates the timestamps of successive sentences in a cycle
+// when skew is enabled, at the hundredths of a second sentences carry
+const sentenceSkew = 20 * time.Millisecond
+
+// SetSentenceTimeSkew sets whether the sentences of a cycle carry slightly
+// later timestamps in turn, as when a receiver generates them one after
+// another, instead of identical times
+func (s *Simulator) SetSentenceTimeSkew(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sentenceTimeSkew = enabled
+}
+
+// ErrNoListener reports that nothing is listening at the destination: a
+// connected UDP socket receives a refusal after an ICMP port-unreachable, and
+// a Unix socket consumer that isn't running refuses or lacks its socket file
+var ErrNoListener = errors.New("no listener on the destination port")
+
+// recordTransmit records the outcome of a transmit cycle for health checks
+func (s *Simulator) recordTransmit(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.lastTransmit = time.Now()
+		s.lastError = nil
+		return
+	}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) && !errors.Is(err, syscall.ENOENT) {
+		s.lastError = fmt.Errorf("failed to transmit sentences: %w", err)
+		return
+	}
+
+	s.lastError = fmt.Errorf("failed to transmit sentences: %w", ErrNoListener)
+
+	// By default keep sending, as a listener may start later
+	if s.stopOnNoListener && s.running {
+		s.running = false
+		close(s.stopChan)
+	}
+}
+
+// SetStopOnNoListener sets whether transmission stops when the destination
+// reports that nothing is listening, rather than retrying every cycle
+func (s *Simulator) SetStopOnNoListener(stop bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopOnNoListener = stop
+}
+
+// IsHealthy reports whether the simulator is running and has successfully
+// transmitted recently. A stalled or failing feed is unhealthy
+func (s *Simulator) IsHealthy() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.running {
+		return false
+	}
+
+	// Allow a few missed cycles before declaring a stall
+	threshold := 3 * s.transmitRate
+	if threshold < 5*time.Second {
+		threshold = 5 * time.Second
+	}
+
+	return time.Since(s.lastTransmit) <= threshold
+}
+
+// LastTransmit returns the time of the last successful transmission
+func (s *Simulator) LastTransmit() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastTransmit
+}
+
+// LastError returns the error from the most recent transmission, or nil once
+// a transmission has succeeded since
+func (s *Simulator) LastError() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastError
+}
+
+// recordSentences adds transmitted sentences to the recent sentence ring buffer
+func (s *Simulator) recordSentences(sentences []string) {
+	const recentCapacity = 200
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sentence := range sentences {
+		if sentence == "" {
+			continue
+		}
+		if len(s.recent) < recentCapacity {
+			s.recent = append(s.recent, sentence)
+		} else {
+			s.recent[s.recentNext] = sentence
+		}
+		s.recentNext = (s.recentNext + 1) % recentCapacity
+	}
+}
+
+// GetRecentSentences returns up to n of the most recently transmitted
+// sentences, oldest first
+func (s *Simulator) GetRecentSentences(n int) []string {
 	s.mu.RLock()
-	state := s.state
-	s.mu.RUnlock()
+	defer s.mu.RUnlock()
 
-	sentences := []string{
-		s.generateGGA(state),
-		s.generateRMC(state),
-		s.generateGLL(state),
-		s.generateVTG(state),
-		s.generateGSA(state),
-		s.generateGSV(state),
+	if n > len(s.recent) {
+		n = len(s.recent)
+	}
+	if n <= 0 {
+		return []string{}
 	}
 
-	for _, sentence := range sentences {
-		if sentence != "" {
-			s.conn.Write([]byte(sentence + "\r\n"))
-		}
+	result := make([]string, n)
+	start := s.recentNext - n
+	for i := range result {
+		result[i] = s.recent[(start+i+len(s.recent))%len(s.recent)]
 	}
+	return result
+}
+
+// multipathJump displaces a position by tens of meters in a random direction
+func (s *Simulator) multipathJump(pos Position) Position {
+	const minJumpMeters = 20.0
+	const maxJumpMeters = 80.0
+
+	jumpNM := (minJumpMeters + s.rng.Float64()*(maxJumpMeters-minJumpMeters)) / 1852
+	pos.Latitude, pos.Longitude = s.calculateNewPosition(
+		pos.Latitude, pos.Longitude, s.rng.Float64()*360, jumpNM)
+
+	return pos
 }
 
 // NMEA sentence generators
 
+// Exported generators produce sentences for a given state at an explicit time,
+// independent of the clock, for deterministic output
+
+// GenerateGGA generates a GGA sentence for state at time t
+func (s *Simulator) GenerateGGA(state NavigationState, t time.Time) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state.Position.Timestamp = t
+	return s.generateGGA(state)
+}
+
+// GenerateRMC generates an RMC sentence for state at time t
+func (s *Simulator) GenerateRMC(state NavigationState, t time.Time) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state.Position.Timestamp = t
+	return s.generateRMC(state)
+}
+
+// GenerateGLL generates a GLL sentence for state at time t
+func (s *Simulator) GenerateGLL(state NavigationState, t time.Time) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state.Position.Timestamp = t
+	return s.generateGLL(state)
+}
+
+// GenerateVTG generates a VTG sentence for state at time t
+func (s *Simulator) GenerateVTG(state NavigationState, t time.Time) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state.Position.Timestamp = t
+	return s.generateVTG(state)
+}
+
+// GenerateGSA generates a GSA sentence for state at time t using the fixed sky
+func (s *Simulator) GenerateGSA(state NavigationState, t time.Time) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state.Position.Timestamp = t
+	return s.generateGSA(state, s.sky)
+}
+
+// GenerateGSV generates the GSV sentences for state at time t using the fixed sky
+func (s *Simulator) GenerateGSV(state NavigationState, t time.Time) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state.Position.Timestamp = t
+	return s.generateGSV(state, s.sky)
+}
+
+// GenerateVBW generates a VBW sentence for state at time t
+func (s *Simulator) GenerateVBW(state NavigationState, t time.Time) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state.Position.Timestamp = t
+	return s.generateVBW(state)
+}
+
 // generateGGA generates a GGA (Global Positioning System Fix Data) sentence
 func (s *Simulator) generateGGA(state NavigationState) string {
 	timeStr := state.Position.Timestamp.Format("150405.00")
 	latStr := s.formatLatitude(state.Position.Latitude)
 	lonStr := s.formatLongitude(state.Position.Longitude)
 
-	sentence := fmt.Sprintf("GPGGA,%s,%s,%s,%d,%02d,%.1f,%.1f,M,0.0,M,,",
-		timeStr, latStr, lonStr, state.FixQuality, state.Satellites, state.HDOP, state.Altitude)
+	// Differential fields are only populated for DGPS, RTK and float RTK fixes
+	dgpsFields := ","
+	switch state.FixQuality {
+	case 2, 4, 5:
+		dgpsFields = fmt.Sprintf("%.1f,%04d", state.DGPSAge, state.DGPSStationID)
+	}
+
+	altitude, unit := state.Altitude, "M"
+	if s.altitudeUnit == AltitudeFeet {
+		altitude, unit = state.Altitude*feetPerMeter, "f"
+	}
+
+	sentence := fmt.Sprintf("GPGGA,%s,%s,%s,%d,%02d,%.1f,%.1f,%s,0.0,%s,%s",
+		timeStr, latStr, lonStr, state.FixQuality, state.Satellites, state.HDOP, altitude, unit, unit, dgpsFields)
 
 	return s.addChecksum(sentence)
 }
@@ -500,8 +1992,12 @@ func (s *Simulator) generateRMC(state NavigationState) string {
 	latStr := s.formatLatitude(state.Position.Latitude)
 	lonStr := s.formatLongitude(state.Position.Longitude)
 
-	sentence := fmt.Sprintf("GPRMC,%s,A,%s,%s,%.1f,%.1f,%s,%.1f,E",
-		timeStr, latStr, lonStr, state.Speed, state.Course, dateStr, math.Abs(state.MagneticVar))
+	sentence := fmt.Sprintf("GPRMC,%s,%s,%s,%s,%.1f,%s,%s,%.1f,%s",
+		timeStr, s.fixStatus(state), latStr, lonStr, state.Speed, s.courseField(state, state.Course), dateStr,
+		math.Abs(state.MagneticVar), eastWest(state.MagneticVar))
+	if s.hasModeIndicator() {
+		sentence += "," + s.modeIndicator(state)
+	}
 
 	return s.addChecksum(sentence)
 }
@@ -512,47 +2008,286 @@ func (s *Simulator) generateGLL(state NavigationState) string {
 	latStr := s.formatLatitude(state.Position.Latitude)
 	lonStr := s.formatLongitude(state.Position.Longitude)
 
-	sentence := fmt.Sprintf("GPGLL,%s,%s,%s,A",
-		latStr, lonStr, timeStr)
+	sentence := fmt.Sprintf("GPGLL,%s,%s,%s,%s",
+		latStr, lonStr, timeStr, s.fixStatus(state))
+	if s.hasModeIndicator() {
+		sentence += "," + s.modeIndicator(state)
+	}
 
 	return s.addChecksum(sentence)
 }
 
 // generateVTG generates a VTG (Track Made Good and Ground Speed) sentence
 func (s *Simulator) generateVTG(state NavigationState) string {
-	magneticCourse := state.Course + state.MagneticVar
-	if magneticCourse > 360 {
-		magneticCourse -= 360
-	} else if magneticCourse < 0 {
-		magneticCourse += 360
-	}
+	// Variation is east-positive, so westerly variation adds to the true course,
+	// matching the variation and hemisphere reported in RMC and HDG
+	magneticCourse := s.normalizeCourse(state.Course - state.MagneticVar)
 
 	speedKmh := state.Speed * 1.852 // Convert knots to km/h
 
-	sentence := fmt.Sprintf("GPVTG,%.1f,T,%.1f,M,%.1f,N,%.1f,K",
-		state.Course, magneticCourse, state.Speed, speedKmh)
+	sentence := fmt.Sprintf("GPVTG,%s,T,%s,M,%.1f,N,%.1f,K",
+		s.courseField(state, state.Course), s.courseField(state, magneticCourse), state.Speed, speedKmh)
+
+	// NMEA 2.3 adds the FAA mode indicator
+	if s.hasModeIndicator() {
+		sentence += "," + s.modeIndicator(state)
+	}
 
 	return s.addChecksum(sentence)
 }
 
+// stoppedSpeedThreshold is the speed in knots below which a vessel counts as
+// stopped for blanking the course
+const stoppedSpeedThreshold = 0.1
+
+// courseField formats a course over ground for output, leaving it empty when
+// the vessel is stopped and blanking is enabled, as real receivers do when
+// the course has no meaning
+func (s *Simulator) courseField(state NavigationState, course float64) string {
+	if s.blankStoppedCourse && state.Speed < stoppedSpeedThreshold {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", course)
+}
+
+// SetBlankCourseWhenStopped sets whether course over ground is left empty in
+// RMC and VTG while the vessel is stopped
+func (s *Simulator) SetBlankCourseWhenStopped(blank bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blankStoppedCourse = blank
+}
+
 // generateGSA generates a GSA (GPS DOP and active satellites) sentence
-func (s *Simulator) generateGSA(state NavigationState) string {
-	sentence := fmt.Sprintf("GPGSA,A,3,01,02,03,04,05,06,07,08,,,,,%.1f,%.1f,%.1f",
-		state.HDOP*1.5, state.HDOP, state.HDOP*0.8) // PDOP, HDOP, VDOP
+func (s *Simulator) generateGSA(state NavigationState, sky []satellite) string {
+	if state.FixQuality == 0 {
+		return s.addChecksum("GPGSA,A,1,,,,,,,,,,,,,,,")
+	}
+
+	// Twelve PRN slots, filled with the satellites used in the fix
+	const prnSlots = 12
+	prns := ""
+	for i := 0; i < prnSlots; i++ {
+		if i < len(sky) && i < state.Satellites {
+			prns += fmt.Sprintf("%02d", sky[i].PRN)
+		}
+		prns += ","
+	}
+
+	sentence := fmt.Sprintf("GPGSA,A,3,%s%.1f,%.1f,%.1f",
+		prns, state.HDOP*1.5, state.HDOP, state.HDOP*0.8) // PDOP, HDOP, VDOP
+
+	return s.addChecksum(sentence)
+}
+
+// generateGSV generates the GSV (GPS Satellites in view) sentences, four
+// satellites per sentence
+func (s *Simulator) generateGSV(state NavigationState, sky []satellite) []string {
+	return s.generateTalkerGSV("GP", sky)
+}
+
+// generateTalkerGSV generates GSV sentences for sky under the given talker ID
+func (s *Simulator) generateTalkerGSV(talker string, sky []satellite) []string {
+	const satellitesPerSentence = 4
+
+	total := (len(sky) + satellitesPerSentence - 1) / satellitesPerSentence
+	if total == 0 {
+		return []string{s.addChecksum(talker + "GSV,1,1,00")}
+	}
+
+	sentences := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		sentence := fmt.Sprintf("%sGSV,%d,%d,%02d", talker, total, i+1, len(sky))
+
+		end := (i + 1) * satellitesPerSentence
+		if end > len(sky) {
+			end = len(sky)
+		}
+		for _, sat := range sky[i*satellitesPerSentence : end] {
+			sentence += fmt.Sprintf(",%02d,%02d,%03d,", sat.PRN, sat.Elevation, sat.Azimuth)
+			if sat.SNR > 0 {
+				sentence += fmt.Sprintf("%02d", sat.SNR)
+			}
+		}
+
+		sentences = append(sentences, s.addChecksum(sentence))
+	}
+
+	return sentences
+}
+
+// generateVBW generates a VBW (Dual Ground/Water Speed) sentence
+func (s *Simulator) generateVBW(state NavigationState) string {
+	waterLong, waterTrans := s.waterVelocity(state)
+	groundLong, groundTrans := s.groundVelocity(state)
+
+	groundStatus := "A"
+	if state.FixQuality == 0 {
+		groundStatus = "V"
+	}
+
+	sentence := fmt.Sprintf("VDVBW,%.1f,%.1f,A,%.1f,%.1f,%s",
+		tenths(waterLong), tenths(waterTrans), tenths(groundLong), tenths(groundTrans), groundStatus)
 
 	return s.addChecksum(sentence)
 }
 
-// generateGSV generates a GSV (GPS Satellites in view) sentence
-func (s *Simulator) generateGSV(state NavigationState) string {
-	// Simplified GSV with simulated satellite data
-	sentence := fmt.Sprintf("GPGSV,2,1,08,01,45,045,45,02,30,120,42,03,60,180,48,04,15,270,35")
+// generateGBS generates a GBS (GNSS Satellite Fault Detection) sentence with
+// expected position errors derived from the DOP values
+func (s *Simulator) generateGBS(state NavigationState, faultPRN int) string {
+	timeStr := state.Position.Timestamp.Format("150405.00")
+
+	if state.FixQuality == 0 {
+		return s.addChecksum(fmt.Sprintf("GPGBS,%s,,,,,,,", timeStr))
+	}
+
+	// User equivalent range error scaled by DOP, matching the GSA DOP values
+	const uereMeters = 5.0
+	errHoriz := state.HDOP * uereMeters / math.Sqrt2
+	errAlt := state.HDOP * 0.8 * uereMeters
+
+	faultFields := ",,,"
+	if faultPRN != 0 {
+		// Excluding the faulty satellite degrades the solution
+		errHoriz *= 1.5
+		errAlt *= 1.5
+		faultFields = fmt.Sprintf("%02d,0.001,%.1f,%.1f", faultPRN, 25.0, uereMeters)
+	}
+
+	sentence := fmt.Sprintf("GPGBS,%s,%.1f,%.1f,%.1f,%s",
+		timeStr, errHoriz, errHoriz, errAlt, faultFields)
+
 	return s.addChecksum(sentence)
 }
 
+// waterVelocity returns the longitudinal and transverse (positive to starboard)
+// speed through the water in knots, removing the current from the ground velocity
+func (s *Simulator) waterVelocity(state NavigationState) (float64, float64) {
+	courseRad := state.Course * math.Pi / 180
+	headingRad := s.heading(state) * math.Pi / 180
+	setRad := state.CurrentSet * math.Pi / 180
+
+	north := state.Speed*math.Cos(courseRad) - state.CurrentDrift*math.Cos(setRad)
+	east := state.Speed*math.Sin(courseRad) - state.CurrentDrift*math.Sin(setRad)
+
+	longitudinal := north*math.Cos(headingRad) + east*math.Sin(headingRad)
+	transverse := -north*math.Sin(headingRad) + east*math.Cos(headingRad)
+
+	return longitudinal, transverse
+}
+
+// groundVelocity returns the longitudinal and transverse (positive to
+// starboard) speed over ground in knots, from the angle between the course
+// over ground and the heading
+func (s *Simulator) groundVelocity(state NavigationState) (float64, float64) {
+	driftRad := (state.Course - s.heading(state)) * math.Pi / 180
+	return state.Speed * math.Cos(driftRad), state.Speed * math.Sin(driftRad)
+}
+
+// tenths rounds v to one decimal place, so that a component a rounding error
+// below zero isn't sent as "-0.0"
+func tenths(v float64) float64 {
+	if rounded := math.Round(v*10) / 10; rounded != 0 {
+		return rounded
+	}
+	return 0
+}
+
 // Helper functions for NMEA formatting
 
-// formatLatitude formats latitude for NMEA (DDMM.MMMM,N/S)
+// fixStatusTable gives the RMC/GLL data status for each GGA fix quality 0-8
+type fixStatusTable [9]byte
+
+// defaultFixStatus reports every fix as valid and only no fix as void, as the
+// simulator always has
+var defaultFixStatus = fixStatusTable{'V', 'A', 'A', 'A', 'A', 'A', 'A', 'A', 'A'}
+
+// SetFixStatusMapping overrides the RMC/GLL data status ('A' valid or 'V'
+// void) reported for GGA fix qualities, to match a receiver that maps them
+// differently. Qualities not in mapping keep the default status; an empty
+// mapping restores the default table
+func (s *Simulator) SetFixStatusMapping(mapping map[int]rune) error {
+	table := defaultFixStatus
+	for quality, status := range mapping {
+		if quality < 0 || quality >= len(table) {
+			return fmt.Errorf("fix quality %d must be between 0 and %d", quality, len(table)-1)
+		}
+		if status != 'A' && status != 'V' {
+			return fmt.Errorf("status for fix quality %d must be 'A' or 'V'", quality)
+		}
+		table[quality] = byte(status)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fixStatusMap = table
+	return nil
+}
+
+// fixStatus returns the RMC/GLL data status: A (valid) or V (void)
+func (s *Simulator) fixStatus(state NavigationState) string {
+	if state.FixQuality < 0 || state.FixQuality >= len(s.fixStatusMap) {
+		return "V"
+	}
+	return string(s.fixStatusMap[state.FixQuality])
+}
+
+// NMEA 0183 versions that change sentence layout
+const (
+	NMEAVersion21 = "2.1" // before the mode indicator was added
+	NMEAVersion23 = "2.3" // adds the mode indicator to RMC, GLL and VTG
+)
+
+// ValidateNMEAVersion checks that version is a supported NMEA 0183 version
+func ValidateNMEAVersion(version string) error {
+	switch version {
+	case NMEAVersion21, NMEAVersion23:
+		return nil
+	}
+	return fmt.Errorf("unsupported NMEA version %q: use %q or %q", version, NMEAVersion21, NMEAVersion23)
+}
+
+// SetNMEAVersion sets the NMEA 0183 version whose sentence layout is followed
+func (s *Simulator) SetNMEAVersion(version string) error {
+	if err := ValidateNMEAVersion(version); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nmeaVersion = version
+	return nil
+}
+
+// hasModeIndicator reports whether sentences carry the NMEA 2.3 mode indicator
+func (s *Simulator) hasModeIndicator() bool {
+	return s.nmeaVersion != NMEAVersion21
+}
+
+// modeIndicator returns the NMEA 2.3 positioning mode indicator for the fix quality
+func (s *Simulator) modeIndicator(state NavigationState) string {
+	switch state.FixQuality {
+	case 0:
+		return "N" // not valid
+	case 2:
+		return "D" // differential
+	case 4:
+		return "R" // RTK
+	case 5:
+		return "F" // float RTK
+	case 6:
+		return "E" // estimated (dead reckoning)
+	case 7:
+		return "M" // manual input
+	case 8:
+		return "S" // simulator
+	default:
+		return "A" // autonomous
+	}
+}
+
+// formatLatitude formats latitude for NMEA (DDMM.MMMM,N/S) with the configured
+// number of decimal places of minutes
 func (s *Simulator) formatLatitude(lat float64) string {
 	var hemisphere string
 	if lat >= 0 {
@@ -562,13 +2297,13 @@ func (s *Simulator) formatLatitude(lat float64) string {
 		lat = -lat
 	}
 
-	degrees := int(lat)
-	minutes := (lat - float64(degrees)) * 60
+	degrees, minutes := s.splitDegrees(lat)
 
-	return fmt.Sprintf("%02d%07.4f,%s", degrees, minutes, hemisphere)
+	return fmt.Sprintf("%02d%0*.*f,%s", degrees, s.positionPrecision+3, s.positionPrecision, minutes, hemisphere)
 }
 
-// formatLongitude formats longitude for NMEA (DDDMM.MMMM,E/W)
+// formatLongitude formats longitude for NMEA (DDDMM.MMMM,E/W) with the
+// configured number of decimal places of minutes
 func (s *Simulator) formatLongitude(lon float64) string {
 	var hemisphere string
 	if lon >= 0 {
@@ -578,10 +2313,24 @@ func (s *Simulator) formatLongitude(lon float64) string {
 		lon = -lon
 	}
 
-	degrees := int(lon)
-	minutes := (lon - float64(degrees)) * 60
+	degrees, minutes := s.splitDegrees(lon)
+
+	return fmt.Sprintf("%03d%0*.*f,%s", degrees, s.positionPrecision+3, s.positionPrecision, minutes, hemisphere)
+}
+
+// splitDegrees splits an angle into whole degrees and minutes rounded to the
+// position precision, carrying into the degrees when minutes round up to 60
+func (s *Simulator) splitDegrees(angle float64) (int, float64) {
+	scale := math.Pow(10, float64(s.positionPrecision))
+
+	degrees := int(angle)
+	minutes := math.Round((angle-float64(degrees))*60*scale) / scale
+	if minutes >= 60 {
+		degrees++
+		minutes -= 60
+	}
 
-	return fmt.Sprintf("%03d%07.4f,%s", degrees, minutes, hemisphere)
+	return degrees, minutes
 }
 
 // addChecksum adds NMEA checksum to a sentence
@@ -590,9 +2339,37 @@ func (s *Simulator) addChecksum(sentence string) string {
 	for i := 0; i < len(sentence); i++ {
 		checksum ^= int(sentence[i])
 	}
+	if s.lowercaseChecksum {
+		return fmt.Sprintf("$%s*%02x", sentence, checksum)
+	}
 	return fmt.Sprintf("$%s*%02X", sentence, checksum)
 }
 
+// SetLowercaseChecksum sets whether checksums are written in lowercase hex,
+// reproducing legacy devices and testing that parsers accept either case
+func (s *Simulator) SetLowercaseChecksum(lowercase bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lowercaseChecksum = lowercase
+}
+
+// PositionAhead returns the point distanceNM ahead of the vessel along its
+// current course
+func (s *Simulator) PositionAhead(distanceNM float64) (lat, lon float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.calculateNewPosition(s.state.Position.Latitude, s.state.Position.Longitude,
+		s.state.Course, distanceNM)
+}
+
+// GetSteeringDiagnostics returns the steering computed in the last position
+// update, to show why the vessel steers as it does
+func (s *Simulator) GetSteeringDiagnostics() SteeringDiagnostics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.steering
+}
+
 // GetCurrentState returns the current navigation state (thread-safe)
 func (s *Simulator) GetCurrentState() NavigationState {
 	s.mu.RLock()
@@ -600,6 +2377,21 @@ func (s *Simulator) GetCurrentState() NavigationState {
 	return s.state
 }
 
+// GetSpeeds returns the speed over ground and through the water
+func (s *Simulator) GetSpeeds() Speeds {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	longitudinal, transverse := s.waterVelocity(s.state)
+	stw := math.Hypot(longitudinal, transverse)
+
+	return Speeds{
+		SOG:   s.state.Speed,
+		STW:   stw,
+		Delta: s.state.Speed - stw,
+	}
+}
+
 // GetRoute returns the current route if loaded
 func (s *Simulator) GetRoute() *RTZRoute {
 	s.mu.RLock()
@@ -621,6 +2413,20 @@ func (s *Simulator) GetCurrentWaypoint() int {
 	return s.currentWaypoint
 }
 
+// GetCurrentLeg returns the waypoint indices of the leg being sailed and how
+// far along it the vessel is in nautical miles. It returns -1, -1, 0 when no
+// route leg is active
+func (s *Simulator) GetCurrentLeg() (fromIndex, toIndex int, alongTrackNM float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.route == nil || s.currentWaypoint == 0 || s.currentWaypoint >= len(s.route.Waypoints) {
+		return -1, -1, 0
+	}
+
+	return s.currentWaypoint - 1, s.currentWaypoint, s.calculateAlongTrackDistance()
+}
+
 // GetWaypointCount returns the total number of waypoints in the route
 func (s *Simulator) GetWaypointCount() int {
 	s.mu.RLock()
@@ -631,15 +2437,116 @@ func (s *Simulator) GetWaypointCount() int {
 	return len(s.route.Waypoints)
 }
 
+// WaypointBearingDistance returns the initial great-circle bearing and the
+// distance in NM from waypoint i to waypoint j of the loaded route
+func (s *Simulator) WaypointBearingDistance(i, j int) (bearing, distanceNM float64, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.route == nil {
+		return 0, 0, ErrNoRoute
+	}
+
+	count := len(s.route.Waypoints)
+	if i < 0 || i >= count || j < 0 || j >= count {
+		return 0, 0, fmt.Errorf("%w: indices must be between 0 and %d", ErrInvalidWaypoint, count-1)
+	}
+
+	from, to := s.route.Waypoints[i], s.route.Waypoints[j]
+	bearing = s.calculateCourse(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	distanceNM = s.calculateDistance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	return bearing, distanceNM, nil
+}
+
+// GetRouteBounds returns the box enclosing the loaded route's waypoints. The
+// box takes the narrowest span of longitude, so a route crossing the
+// antimeridian gives minLon greater than maxLon rather than a box around the
+// whole globe
+func (s *Simulator) GetRouteBounds() (minLat, minLon, maxLat, maxLon float64, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.route == nil || len(s.route.Waypoints) == 0 {
+		return 0, 0, 0, 0, ErrNoRoute
+	}
+
+	minLat, maxLat = 90, -90
+	longitudes := make([]float64, len(s.route.Waypoints))
+	for i, wp := range s.route.Waypoints {
+		minLat = math.Min(minLat, wp.Latitude)
+		maxLat = math.Max(maxLat, wp.Latitude)
+		longitudes[i] = wp.Longitude
+	}
+	sort.Float64s(longitudes)
+
+	// The box spans everything except the widest gap between neighbouring
+	// longitudes, starting with the gap across the antimeridian
+	widestGap := longitudes[0] + 360 - longitudes[len(longitudes)-1]
+	minLon, maxLon = longitudes[0], longitudes[len(longitudes)-1]
+	for i := 1; i < len(longitudes); i++ {
+		if gap := longitudes[i] - longitudes[i-1]; gap > widestGap {
+			widestGap = gap
+			minLon, maxLon = longitudes[i], longitudes[i-1]
+		}
+	}
+
+	return minLat, minLon, maxLat, maxLon, nil
+}
+
+// Waypoint jump modes, for manually changing the target waypoint
+const (
+	WaypointJumpTeleport = "teleport" // move the vessel onto the leg leading to the new target
+	WaypointJumpNavigate = "navigate" // keep the vessel where it is and sail to the new target
+)
+
+// SetWaypointJumpMode sets how manual waypoint changes move the vessel. The
+// navigate mode keeps the track continuous for recording and export
+func (s *Simulator) SetWaypointJumpMode(mode string) error {
+	switch mode {
+	case WaypointJumpTeleport, WaypointJumpNavigate:
+	default:
+		return fmt.Errorf("invalid waypoint jump mode %q: use %q or %q", mode, WaypointJumpTeleport, WaypointJumpNavigate)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jumpMode = mode
+	return nil
+}
+
+// retarget makes waypoint index the target without moving the vessel, which
+// then turns toward it at the normal rate of turn. The caller must hold the
+// write lock
+func (s *Simulator) retarget(index int) {
+	s.currentWaypoint = index
+	s.autoNavigate = true
+	s.routeComplete = false
+	s.arrivalLatch = -1
+	s.endDwell()
+	s.courseHold = false
+	s.turnRadius = 0
+}
+
 // AdvanceToNextWaypoint manually advances to the next waypoint
-func (s *Simulator) AdvanceToNextWaypoint() bool {
+func (s *Simulator) AdvanceToNextWaypoint() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.route == nil || s.currentWaypoint >= len(s.route.Waypoints)-1 {
-		return false
+	if s.route == nil {
+		return ErrNoRoute
+	}
+	if s.currentWaypoint >= len(s.route.Waypoints)-1 {
+		return fmt.Errorf("%w: already at the last waypoint", ErrInvalidWaypoint)
 	}
 
+	if s.jumpMode == WaypointJumpNavigate {
+		s.retarget(s.currentWaypoint + 1)
+		return nil
+	}
+
+	s.endDwell()
+	s.courseHold = false
+
 	// Move to the current waypoint position before advancing
 	currentWP := s.route.Waypoints[s.currentWaypoint]
 	s.state.Position = Position{
@@ -657,24 +2564,45 @@ func (s *Simulator) AdvanceToNextWaypoint() bool {
 			targetWP.Latitude, targetWP.Longitude,
 		)
 	} else {
+		// Stay on the final waypoint rather than pointing past the route
+		s.currentWaypoint = len(s.route.Waypoints) - 1
 		s.autoNavigate = false
+		s.routeComplete = true
 		s.state.Speed = 0
 	}
 
-	return true
+	return nil
 }
 
 // GoToPreviousWaypoint manually goes to the previous waypoint
-func (s *Simulator) GoToPreviousWaypoint() bool {
+func (s *Simulator) GoToPreviousWaypoint() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.route == nil || s.currentWaypoint <= 1 {
-		return false
+	if s.route == nil {
+		return ErrNoRoute
+	}
+
+	// Teleporting needs the waypoint before the new target to start from
+	first := 1
+	if s.jumpMode == WaypointJumpNavigate {
+		first = 0
+	}
+	if s.currentWaypoint <= first {
+		return fmt.Errorf("%w: already at the first waypoint", ErrInvalidWaypoint)
+	}
+
+	if s.jumpMode == WaypointJumpNavigate {
+		s.retarget(s.currentWaypoint - 1)
+		return nil
 	}
 
 	s.currentWaypoint--
 	s.autoNavigate = true
+	s.routeComplete = false
+	s.arrivalLatch = -1
+	s.endDwell()
+	s.courseHold = false
 
 	// Move to the previous waypoint position
 	prevWP := s.route.Waypoints[s.currentWaypoint-1]
@@ -691,16 +2619,31 @@ func (s *Simulator) GoToPreviousWaypoint() bool {
 		targetWP.Latitude, targetWP.Longitude,
 	)
 
-	return true
+	return nil
 }
 
 // SetCurrentWaypoint manually sets the current target waypoint
-func (s *Simulator) SetCurrentWaypoint(waypointIndex int) bool {
+func (s *Simulator) SetCurrentWaypoint(waypointIndex int) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.route == nil || waypointIndex < 1 || waypointIndex >= len(s.route.Waypoints) {
-		return false
+	if s.route == nil {
+		return ErrNoRoute
+	}
+
+	// Teleporting needs the waypoint before the new target to start from
+	first := 1
+	if s.jumpMode == WaypointJumpNavigate {
+		first = 0
+	}
+	if waypointIndex < first || waypointIndex >= len(s.route.Waypoints) {
+		return fmt.Errorf("%w: index %d must be between %d and %d",
+			ErrInvalidWaypoint, waypointIndex, first, len(s.route.Waypoints)-1)
+	}
+
+	if s.jumpMode == WaypointJumpNavigate {
+		s.retarget(waypointIndex)
+		return nil
 	}
 
 	// Move to the previous waypoint position (where vessel should be)
@@ -713,6 +2656,10 @@ func (s *Simulator) SetCurrentWaypoint(waypointIndex int) bool {
 
 	s.currentWaypoint = waypointIndex
 	s.autoNavigate = true
+	s.routeComplete = false
+	s.arrivalLatch = -1
+	s.endDwell()
+	s.courseHold = false
 
 	// Set course to the target waypoint
 	targetWP := s.route.Waypoints[s.currentWaypoint]
@@ -721,7 +2668,50 @@ func (s *Simulator) SetCurrentWaypoint(waypointIndex int) bool {
 		targetWP.Latitude, targetWP.Longitude,
 	)
 
-	return true
+	return nil
+}
+
+// SetAutoNavigate enables or disables automatic waypoint following while
+// keeping the route loaded. When re-enabled the vessel heads for the nearest
+// waypoint
+func (s *Simulator) SetAutoNavigate(enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.route == nil {
+		return ErrNoRoute
+	}
+
+	s.autoNavigate = enabled
+	if !enabled {
+		return nil
+	}
+
+	nearest := 0
+	nearestDistance := math.Inf(1)
+	for i, wp := range s.route.Waypoints {
+		distance := s.calculateDistance(
+			s.state.Position.Latitude, s.state.Position.Longitude,
+			wp.Latitude, wp.Longitude,
+		)
+		if distance < nearestDistance {
+			nearest = i
+			nearestDistance = distance
+		}
+	}
+
+	s.currentWaypoint = nearest
+	s.routeComplete = false
+	s.arrivalLatch = -1
+	s.endDwell()
+	s.courseHold = false
+	targetWP := s.route.Waypoints[nearest]
+	s.state.Course = s.calculateCourse(
+		s.state.Position.Latitude, s.state.Position.Longitude,
+		targetWP.Latitude, targetWP.Longitude,
+	)
+
+	return nil
 }
 
 // GetWaypointInfo returns current waypoint status information
@@ -732,19 +2722,64 @@ func (s *Simulator) GetWaypointInfo() WaypointInfo {
 	info := WaypointInfo{
 		CurrentWaypoint: s.currentWaypoint,
 		AutoNavigate:    s.autoNavigate,
+		ArrivalRadius:   s.arrivalRadius,
 	}
 
 	if s.route != nil {
 		info.TotalWaypoints = len(s.route.Waypoints)
-		if s.currentWaypoint >= 0 && s.currentWaypoint < len(s.route.Waypoints) {
+
+		// The vessel holds position at zero speed, so the route never progresses
+		info.Stalled = s.autoNavigate && s.state.Speed <= 0 && s.dwellLeft <= 0
+		info.DwellRemaining = s.dwellLeft.Seconds()
+
+		switch {
+		case len(s.route.Waypoints) == 1:
+			info.Warning = "route has a single waypoint - there is no leg to follow, so waypoint navigation is off"
+		case info.Stalled:
+			info.Warning = "stalled - speed is zero"
+		}
+		if s.routeComplete {
+			// The final waypoint has been reached, so there is nothing to steer for
+			finalWP := s.route.Waypoints[len(s.route.Waypoints)-1]
+			info.Completed = true
+			info.ReachedWaypoint = &finalWP
+			info.TargetName = finalWP.Identifier()
+		} else if s.currentWaypoint >= 0 && s.currentWaypoint < len(s.route.Waypoints) {
 			targetWP := s.route.Waypoints[s.currentWaypoint]
 			info.TargetWaypoint = &targetWP
+			info.TargetName = targetWP.Identifier()
 			info.DistanceToTarget = s.calculateDistance(
 				s.state.Position.Latitude, s.state.Position.Longitude,
 				targetWP.Latitude, targetWP.Longitude,
 			)
 		}
+
+		info.TotalDistance = s.legDistance(0)
+
+		if !s.routeComplete {
+			info.RemainingDistance = info.DistanceToTarget + s.legDistance(s.currentWaypoint)
+		}
+
+		switch {
+		case s.routeComplete:
+			info.ProgressPercent = 100
+		case info.TotalDistance > 0:
+			progress := (info.TotalDistance - info.RemainingDistance) / info.TotalDistance * 100
+			info.ProgressPercent = math.Max(0, math.Min(100, progress))
+		}
 	}
 
 	return info
 }
+
+// legDistance returns the total length of the route legs from waypoint index
+// from to the final waypoint in nautical miles
+func (s *Simulator) legDistance(from int) float64 {
+	total := 0.0
+	for i := from + 1; i < len(s.route.Waypoints); i++ {
+		prev := s.route.Waypoints[i-1]
+		wp := s.route.Waypoints[i]
+		total += s.calculateDistance(prev.Latitude, prev.Longitude, wp.Latitude, wp.Longitude)
+	}
+	return total
+}
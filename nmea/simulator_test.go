@@ -0,0 +1,369 @@
+package nmea
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestSimulator returns a simulator that transmits nothing, for stepping
+// through simulated time
+func newTestSimulator(t *testing.T) *Simulator {
+	t.Helper()
+
+	s, err := NewSimulatorWithTransport(SimulatorConfig{TransmitRate: time.Second}, discardTransport{})
+	if err != nil {
+		t.Fatalf("NewSimulatorWithTransport: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// testRoute builds a route through the given latitude, longitude pairs
+func testRoute(points ...[2]float64) *RTZRoute {
+	route := &RTZRoute{}
+	for i, p := range points {
+		route.Waypoints = append(route.Waypoints, Waypoint{ID: strconv.Itoa(i), Latitude: p[0], Longitude: p[1]})
+	}
+	return route
+}
+
+func TestRouteTurnsAtRateOfTurn(t *testing.T) {
+	s := newTestSimulator(t)
+
+	// A right-angle turn at each waypoint
+	route := testRoute([2]float64{50, 0}, [2]float64{50.05, 0}, [2]float64{50.05, 0.08}, [2]float64{50, 0.08})
+	if err := s.SetRoute(route, 12); err != nil {
+		t.Fatalf("SetRoute: %v", err)
+	}
+
+	const maxTurnPerStep = 3.0 // degrees in a one-second step
+	previous := s.GetCurrentState().Course
+	for i := 0; i < 3600 && !s.routeComplete; i++ {
+		s.Step(time.Second)
+		course := s.GetCurrentState().Course
+		if turn := s.courseDifference(previous, course); turn > maxTurnPerStep+1e-9 {
+			t.Fatalf("step %d: course changed %.2f degrees, from %.2f to %.2f", i, turn, previous, course)
+		}
+		previous = course
+	}
+
+	if !s.routeComplete {
+		t.Fatal("route not completed")
+	}
+}
+
+func TestInitialCourseHeldUntilTargetPassedAbeam(t *testing.T) {
+	s := newTestSimulator(t)
+
+	if err := s.SetRoute(testRoute([2]float64{50, 0}, [2]float64{50.1, 0}), 10); err != nil {
+		t.Fatalf("SetRoute: %v", err)
+	}
+	s.SetInitialCourse(45)
+
+	// Abeam of the target after about 25 minutes at 10 knots
+	s.Step(20 * time.Minute)
+	if course := s.GetCurrentState().Course; course != 45 {
+		t.Errorf("course after 20 minutes = %.2f, want 45 held", course)
+	}
+
+	s.Step(10 * time.Minute)
+	if s.courseHold {
+		t.Error("course still held after passing the target abeam")
+	}
+	if course := s.GetCurrentState().Course; math.Abs(course-45) < 1 {
+		t.Errorf("course after passing abeam = %.2f, want turning toward the target", course)
+	}
+}
+
+func TestArrivalJitterAdvancesOnce(t *testing.T) {
+	s := newTestSimulator(t)
+
+	// The final waypoint lies just past the second, inside the hysteresis band
+	const nm = 1.0 / 60 // degrees of latitude
+	route := testRoute([2]float64{50, 0}, [2]float64{50 + 0.6*nm, 0}, [2]float64{50 + 0.615*nm, 0})
+	if err := s.SetRoute(route, 10); err != nil {
+		t.Fatalf("SetRoute: %v", err)
+	}
+
+	// Hover either side of the arrival radius, just past waypoint 1
+	for i := 0; i < 10; i++ {
+		offset := DefaultArrivalRadius - 0.001
+		if i%2 == 1 {
+			offset = DefaultArrivalRadius + 0.001
+		}
+		s.state.Position.Latitude = 50 + (0.6+offset)*nm
+		s.checkWaypointProximity()
+	}
+
+	if s.currentWaypoint != 2 || s.routeComplete {
+		t.Fatalf("after jitter: target %d, complete %v; want target 2 and not complete", s.currentWaypoint, s.routeComplete)
+	}
+
+	// Once clear of waypoint 1 by the hysteresis, waypoint 2 can be reached
+	s.state.Position.Latitude = 50 + (0.6+DefaultArrivalRadius+DefaultArrivalHysteresis+0.001)*nm
+	s.checkWaypointProximity()
+	if !s.routeComplete {
+		t.Error("final waypoint not reached once clear of the previous one")
+	}
+}
+
+func TestDensifyClearsArrivalLatch(t *testing.T) {
+	s := newTestSimulator(t)
+
+	// Legs of 0.03 NM, which densifying splits at their midpoints
+	const nm = 1.0 / 60 // degrees of latitude
+	route := testRoute([2]float64{50, 0}, [2]float64{50 + 0.03*nm, 0}, [2]float64{50 + 0.06*nm, 0})
+	if err := s.SetRoute(route, 1); err != nil {
+		t.Fatalf("SetRoute: %v", err)
+	}
+
+	// Reach waypoint 1, latching it
+	s.state.Position.Latitude = 50 + 0.03*nm
+	s.checkWaypointProximity()
+	if s.currentWaypoint != 2 || s.arrivalLatch != 1 {
+		t.Fatalf("at waypoint 1: target %d, latch %d; want target 2, latch 1", s.currentWaypoint, s.arrivalLatch)
+	}
+
+	// Index 1 of the densified route is the point inserted just behind the
+	// vessel, which a stale latch would wait to clear
+	if err := s.DensifyRoute(0.02); err != nil {
+		t.Fatalf("DensifyRoute: %v", err)
+	}
+	if s.currentWaypoint != 3 {
+		t.Fatalf("densified target %d, want 3", s.currentWaypoint)
+	}
+
+	// The next point lies inside the arrival radius, so it is reached at once
+	s.checkWaypointProximity()
+	if s.currentWaypoint != 4 {
+		t.Errorf("target %d after the next check, want 4", s.currentWaypoint)
+	}
+}
+
+func TestCourseInputsNormalized(t *testing.T) {
+	tests := []struct {
+		course float64
+		want   float64
+		vtg    string // true course field of VTG
+	}{
+		{450, 90, "90.0"},
+		{-10, 350, "350.0"},
+		{360, 0, "0.0"},
+		{720.5, 0.5, "0.5"},
+		{-370, 350, "350.0"},
+		{123.4, 123.4, "123.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.FormatFloat(tt.course, 'f', -1, 64), func(t *testing.T) {
+			s := newTestSimulator(t)
+
+			s.UpdateCourse(tt.course)
+			state := s.GetCurrentState()
+			if math.Abs(state.Course-tt.want) > 1e-9 {
+				t.Errorf("UpdateCourse(%v): course %v, want %v", tt.course, state.Course, tt.want)
+			}
+			state.Speed = 5
+			if fields := strings.Split(s.GenerateVTG(state, generatorTime), ","); fields[1] != tt.vtg {
+				t.Errorf("UpdateCourse(%v): VTG course %q, want %q", tt.course, fields[1], tt.vtg)
+			}
+
+			s.SetPosition(50, -1, 5, tt.course)
+			if course := s.GetCurrentState().Course; math.Abs(course-tt.want) > 1e-9 {
+				t.Errorf("SetPosition course %v: course %v, want %v", tt.course, course, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoincidentWaypointsSkipped(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    [][2]float64
+		wantCount int
+	}{
+		{"duplicate start", [][2]float64{{50, 0}, {50, 0}, {50.05, 0}}, 2},
+		{"duplicate middle", [][2]float64{{50, 0}, {50.05, 0}, {50.05, 0}, {50.05, 0.05}}, 3},
+		{"triplicate end", [][2]float64{{50, 0}, {50.05, 0}, {50.05, 0}, {50.05, 0}}, 2},
+		{"within threshold", [][2]float64{{50, 0}, {50.05, 0}, {50.05, 0.000001}, {50.1, 0}}, 3},
+		{"all coincident", [][2]float64{{50, 0}, {50, 0}, {50, 0}}, 1},
+		{"distinct", [][2]float64{{50, 0}, {50.05, 0}, {50.1, 0}}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+
+			if err := s.SetRoute(testRoute(tt.points...), 12); err != nil {
+				t.Fatalf("SetRoute: %v", err)
+			}
+			if got := len(s.route.Waypoints); got != tt.wantCount {
+				t.Fatalf("%d waypoints kept, want %d", got, tt.wantCount)
+			}
+
+			for i := 0; i < 3600 && !s.routeComplete; i++ {
+				s.Step(time.Second)
+				state := s.GetCurrentState()
+				if math.IsNaN(state.Course) || math.IsNaN(state.Position.Latitude) || math.IsNaN(state.Position.Longitude) {
+					t.Fatalf("step %d: NaN in state %+v", i, state)
+				}
+			}
+			if !s.routeComplete {
+				t.Error("route not completed")
+			}
+		})
+	}
+}
+
+func TestNonPositiveTransmitRateDefaults(t *testing.T) {
+	tests := []struct {
+		rate time.Duration
+		want time.Duration
+	}{
+		{0, time.Second},
+		{-5 * time.Second, time.Second},
+		{-1, time.Second},
+		{200 * time.Millisecond, 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rate.String(), func(t *testing.T) {
+			s, err := NewSimulatorWithTransport(SimulatorConfig{TransmitRate: tt.rate}, discardTransport{})
+			if err != nil {
+				t.Fatalf("NewSimulatorWithTransport: %v", err)
+			}
+			defer s.Close()
+
+			if s.transmitRate != tt.want {
+				t.Errorf("transmit rate %s, want %s", s.transmitRate, tt.want)
+			}
+
+			// Starting creates the transmit ticker, which panics on a non-positive rate
+			if err := s.Start(); err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+			s.Stop()
+		})
+	}
+}
+
+func TestRouteCompletionBoundary(t *testing.T) {
+	threeLegs := [][2]float64{{50, 0}, {50.02, 0}, {50.04, 0}, {50.06, 0}}
+
+	tests := []struct {
+		name          string
+		points        [][2]float64
+		endOfRoute    string
+		sail          time.Duration
+		jumpBack      bool
+		wantCompleted bool
+		wantTarget    int // index of the target waypoint, -1 for none
+	}{
+		{"single waypoint", [][2]float64{{50, 0}}, EndOfRouteStop, 0, false, true, -1},
+		{"under way", threeLegs, EndOfRouteStop, time.Minute, false, false, 1},
+		{"final leg", threeLegs, EndOfRouteStop, 18 * time.Minute, false, false, 3},
+		{"stopped at the end", threeLegs, EndOfRouteStop, time.Hour, false, true, -1},
+		{"continued past the end", threeLegs, EndOfRouteContinue, time.Hour, false, true, -1},
+		{"looping", threeLegs, EndOfRouteLoop, time.Hour, false, false, -2},
+		{"sent back after completing", threeLegs, EndOfRouteStop, time.Hour, true, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+			if err := s.SetEndOfRouteBehavior(tt.endOfRoute); err != nil {
+				t.Fatalf("SetEndOfRouteBehavior: %v", err)
+			}
+			if err := s.SetRoute(testRoute(tt.points...), 10); err != nil {
+				t.Fatalf("SetRoute: %v", err)
+			}
+			s.Step(tt.sail)
+			if tt.jumpBack {
+				if err := s.GoToPreviousWaypoint(); err != nil {
+					t.Fatalf("GoToPreviousWaypoint: %v", err)
+				}
+			}
+
+			info := s.GetWaypointInfo()
+			if info.Completed != tt.wantCompleted {
+				t.Fatalf("completed = %v, want %v", info.Completed, tt.wantCompleted)
+			}
+
+			if tt.wantCompleted {
+				final := tt.points[len(tt.points)-1]
+				if info.ReachedWaypoint == nil || info.ReachedWaypoint.Latitude != final[0] {
+					t.Errorf("reached waypoint %+v, want the final waypoint", info.ReachedWaypoint)
+				}
+				if info.TargetWaypoint != nil || info.DistanceToTarget != 0 || info.ProgressPercent != 100 {
+					t.Errorf("completed route still targets %+v at %.3f NM, %.0f%% progress",
+						info.TargetWaypoint, info.DistanceToTarget, info.ProgressPercent)
+				}
+				if info.CurrentWaypoint != len(tt.points)-1 {
+					t.Errorf("current waypoint %d, want the final index %d", info.CurrentWaypoint, len(tt.points)-1)
+				}
+				return
+			}
+
+			if info.ReachedWaypoint != nil || info.TargetWaypoint == nil {
+				t.Fatalf("route in progress: reached %+v, target %+v", info.ReachedWaypoint, info.TargetWaypoint)
+			}
+			if tt.wantTarget >= 0 && info.CurrentWaypoint != tt.wantTarget {
+				t.Errorf("target waypoint %d, want %d", info.CurrentWaypoint, tt.wantTarget)
+			}
+		})
+	}
+}
+
+func TestAsternHeadingOppositeCourse(t *testing.T) {
+	s := newTestSimulator(t)
+	s.SetPosition(50, 0, 5, 90)
+
+	if err := s.SetAstern(3); err != nil {
+		t.Fatalf("SetAstern: %v", err)
+	}
+	state := s.GetCurrentState()
+	if !s.IsAstern() || state.Course != 270 || s.GetHeading() != 90 || state.Speed != 3 {
+		t.Errorf("astern: astern %v, course %.1f, heading %.1f, speed %.1f; want true, 270, 90, 3",
+			s.IsAstern(), state.Course, s.GetHeading(), state.Speed)
+	}
+
+	s.UpdateSpeed(5)
+	state = s.GetCurrentState()
+	if s.IsAstern() || state.Course != 90 || s.GetHeading() != 90 {
+		t.Errorf("ahead: astern %v, course %.1f, heading %.1f; want false, 90, 90",
+			s.IsAstern(), state.Course, s.GetHeading())
+	}
+}
+
+func TestOdometerResets(t *testing.T) {
+	tests := []struct {
+		name  string
+		reset func(s *Simulator) error
+	}{
+		{"start", (*Simulator).Start},
+		{"stop", func(s *Simulator) error { s.Stop(); return nil }},
+		{"route load", func(s *Simulator) error { return s.SetRoute(testRoute([2]float64{50, 0}, [2]float64{51, 0}), 10) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSimulator(t)
+			s.SetPosition(50, 0, 12, 90)
+
+			s.Step(time.Hour)
+			if distance := s.GetDistanceTraveled(); math.Abs(distance-12) > 0.01 {
+				t.Fatalf("distance after an hour at 12 knots %.3f NM, want 12", distance)
+			}
+
+			if err := tt.reset(s); err != nil {
+				t.Fatalf("reset: %v", err)
+			}
+			if distance := s.GetDistanceTraveled(); distance != 0 {
+				t.Errorf("distance %.3f NM after %s, want 0", distance, tt.name)
+			}
+		})
+	}
+}
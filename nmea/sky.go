@@ -0,0 +1,174 @@
+package nmea
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// satellite is a simulated satellite in view
+type satellite struct {
+	PRN       int
+	Elevation int // degrees above the horizon
+	Azimuth   int // degrees true
+	SNR       int // signal-to-noise ratio in dB-Hz, 0 when not tracked
+	System    int // GNSS system ID when systems are set, otherwise 0
+}
+
+// MaxSatellites is the largest number of satellites that can be simulated in view
+const MaxSatellites = 24
+
+// skyForCount returns a fixed sky of count satellites. The first eight keep
+// their familiar positions; the rest are spread around the sky
+func skyForCount(count int) []satellite {
+	sky := defaultSky()
+	if count <= len(sky) {
+		return sky[:count]
+	}
+
+	for prn := len(sky) + 1; prn <= count; prn++ {
+		elevation := 10 + (prn*37)%75
+		sky = append(sky, satellite{
+			PRN:       prn,
+			Elevation: elevation,
+			Azimuth:   (prn * 97) % 360,
+			SNR:       30 + elevation/5,
+		})
+	}
+
+	return sky
+}
+
+// defaultSky returns the fixed set of satellites in view
+func defaultSky() []satellite {
+	return []satellite{
+		{PRN: 1, Elevation: 45, Azimuth: 45, SNR: 45},
+		{PRN: 2, Elevation: 30, Azimuth: 120, SNR: 42},
+		{PRN: 3, Elevation: 60, Azimuth: 180, SNR: 48},
+		{PRN: 4, Elevation: 15, Azimuth: 270, SNR: 35},
+		{PRN: 5, Elevation: 50, Azimuth: 300, SNR: 44},
+		{PRN: 6, Elevation: 25, Azimuth: 20, SNR: 38},
+		{PRN: 7, Elevation: 70, Azimuth: 90, SNR: 47},
+		{PRN: 8, Elevation: 10, Azimuth: 200, SNR: 30},
+	}
+}
+
+// SetSatelliteCount sets the number of satellites in view and used in the fix
+func (s *Simulator) SetSatelliteCount(count int) error {
+	if count < 0 || count > MaxSatellites {
+		return fmt.Errorf("satellite count must be between 0 and %d", MaxSatellites)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sky = assignSystems(skyForCount(count), s.gnssSystems)
+	s.state.Satellites = count
+	return nil
+}
+
+// SimulateSatelliteFault flags the satellite with the given PRN as faulty in
+// GBS integrity reports. A PRN of 0 clears the fault
+func (s *Simulator) SimulateSatelliteFault(prn int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prn != 0 {
+		found := false
+		for _, sat := range s.sky {
+			if sat.PRN == prn {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("satellite %d is not in view", prn)
+		}
+	}
+
+	s.faultPRN = prn
+	return nil
+}
+
+// SetDynamicSky enables speed and turn dependent variation of satellite SNR,
+// with occasional dropouts simulating antenna masking
+func (s *Simulator) SetDynamicSky(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dynamicSky = enabled
+	s.lastSkyCourse = s.state.Course
+}
+
+// gpsOrbitalPeriod is the orbital period of a GPS satellite, half a sidereal day
+const gpsOrbitalPeriod = 11*time.Hour + 58*time.Minute
+
+// SetSkyEvolution enables slow movement of the satellites across the sky over
+// the simulation clock, so satellites rise and set over hours. The sky as
+// configured is the starting point
+func (s *Simulator) SetSkyEvolution(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skyEvolution = enabled
+	s.skyEpoch = s.gpsTime(time.Now().UTC())
+}
+
+// evolvedSky moves each satellite of sky along a simplified orbit for elapsed
+// time, leaving out those below the horizon. Each satellite is above the
+// horizon for two thirds of its orbit and sweeps half way round in azimuth
+// per orbit; at zero elapsed time the sky is unchanged
+func evolvedSky(sky []satellite, elapsed time.Duration) []satellite {
+	advance := 2 * math.Pi * elapsed.Seconds() / gpsOrbitalPeriod.Seconds()
+
+	evolved := make([]satellite, 0, len(sky))
+	for i, sat := range sky {
+		// Elevation follows 60*(sin+0.5), so set the phase to match the start,
+		// with alternate satellites rising and setting so they don't move together
+		phase := math.Asin(math.Max(-1, math.Min(1, float64(sat.Elevation)/60-0.5)))
+		if i%2 == 1 {
+			phase = math.Pi - phase
+		}
+		elevation := int(math.Round(60 * (math.Sin(phase+advance) + 0.5)))
+		if elevation <= 0 {
+			continue
+		}
+
+		sat.Elevation = min(elevation, 90)
+		sat.Azimuth = int(math.Round(float64(sat.Azimuth)+advance*90/math.Pi)) % 360
+		sat.SNR = 30 + sat.Elevation/5
+		evolved = append(evolved, sat)
+	}
+	return evolved
+}
+
+// currentSky returns the satellites in view for this transmit cycle. The
+// caller must hold the lock
+func (s *Simulator) currentSky() []satellite {
+	base := s.sky
+	if s.skyEvolution {
+		base = evolvedSky(s.sky, s.gpsTime(time.Now().UTC()).Sub(s.skyEpoch))
+	}
+
+	if !s.dynamicSky {
+		return append([]satellite(nil), base...)
+	}
+
+	// Turning and speed both degrade reception, low satellites most of all
+	turn := s.courseDifference(s.state.Course, s.lastSkyCourse)
+	s.lastSkyCourse = s.state.Course
+	dynamics := math.Abs(s.state.Speed)*0.1 + turn*0.5
+
+	sky := make([]satellite, 0, len(base))
+	for _, sat := range base {
+		lowFactor := 1 + float64(90-sat.Elevation)/90
+
+		dropChance := (0.01 + turn*0.005) * lowFactor
+		if s.rng.Float64() < dropChance {
+			continue
+		}
+
+		dip := dynamics*lowFactor + s.rng.Float64()*3
+		sat.SNR = int(math.Max(10, float64(sat.SNR)-dip))
+		sky = append(sky, sat)
+	}
+
+	return sky
+}
@@ -0,0 +1,81 @@
+// startprofile.go - Cold, warm and hot start satellite acquisition
+package nmea
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Start profiles, modelling how much the receiver knows when it powers up
+const (
+	StartCold = "cold" // no almanac or position: slow search, satellites appear gradually
+	StartWarm = "warm" // almanac and rough position, but no current ephemeris
+	StartHot  = "hot"  // recent ephemeris: locks almost at once
+)
+
+// startProfile describes acquisition after Start for one start type
+type startProfile struct {
+	acquisition time.Duration // time before a fix
+	curve       float64       // exponent shaping the satellite count climb; above 1 starts slowly
+	settle      time.Duration // time after the fix before a differential fix is reported
+}
+
+// startProfiles holds the acquisition timing of each start type, typical of
+// consumer receivers
+var startProfiles = map[string]startProfile{
+	StartCold: {acquisition: 40 * time.Second, curve: 2, settle: 20 * time.Second},
+	StartWarm: {acquisition: 25 * time.Second, curve: 1, settle: 10 * time.Second},
+	StartHot:  {acquisition: 2 * time.Second, curve: 0.5},
+}
+
+// ValidateStartProfile checks a start profile is known. Empty means no
+// profile, leaving the acquisition delay as set
+func ValidateStartProfile(profile string) error {
+	if _, ok := startProfiles[profile]; !ok && profile != "" {
+		return fmt.Errorf("invalid start profile %q: use %q, %q or %q", profile, StartCold, StartWarm, StartHot)
+	}
+	return nil
+}
+
+// SetStartProfile sets how the simulator acquires a fix after Start: "cold",
+// "warm" or "hot". The profile sets the acquisition delay, how quickly
+// satellites come into view, and how long a differential fix takes to follow
+// the first fix
+func (s *Simulator) SetStartProfile(profile string) error {
+	if err := ValidateStartProfile(profile); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applyStartProfile(profile)
+	return nil
+}
+
+// applyStartProfile sets the acquisition timing for a profile. The caller
+// must hold the write lock
+func (s *Simulator) applyStartProfile(profile string) {
+	s.startProfile = startProfiles[profile]
+	if profile != "" {
+		s.acquisition = s.startProfile.acquisition
+	}
+}
+
+// acquiringState returns state as reported while the receiver is still
+// acquiring, elapsed after Start. The caller must hold the lock
+func (s *Simulator) acquiringState(state NavigationState, elapsed time.Duration) NavigationState {
+	if elapsed < s.acquisition {
+		// Still acquiring: no fix, with satellites coming into view over the delay
+		progress := float64(elapsed) / float64(s.acquisition)
+		if s.startProfile.curve > 0 {
+			progress = math.Pow(progress, s.startProfile.curve)
+		}
+		state.FixQuality = 0
+		state.Satellites = int(float64(state.Satellites) * progress)
+	} else if elapsed < s.acquisition+s.startProfile.settle && state.FixQuality > 1 {
+		// Corrections aren't applied until the fix has settled
+		state.FixQuality = 1
+	}
+	return state
+}
@@ -0,0 +1,77 @@
+package nmea
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ValidateState checks that a navigation state can be encoded as valid sentences
+func ValidateState(state NavigationState) error {
+	values := []float64{
+		state.Position.Latitude, state.Position.Longitude, state.Speed, state.Course,
+		state.MagneticVar, state.HDOP, state.Altitude, state.CurrentSet, state.CurrentDrift, state.DGPSAge,
+	}
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("state contains a non-finite value")
+		}
+	}
+
+	switch {
+	case state.Position.Latitude < -90 || state.Position.Latitude > 90:
+		return fmt.Errorf("latitude must be between -90 and 90")
+	case state.Position.Longitude < -180 || state.Position.Longitude > 180:
+		return fmt.Errorf("longitude must be between -180 and 180")
+	case state.Speed < 0:
+		return fmt.Errorf("speed cannot be negative")
+	case math.Abs(state.MagneticVar) > 180:
+		return fmt.Errorf("magnetic variation must be between -180 and 180")
+	case state.FixQuality < 0 || state.FixQuality > 8:
+		return fmt.Errorf("fix quality must be between 0 and 8")
+	case state.Satellites < 0 || state.Satellites > MaxSatellites:
+		return fmt.Errorf("satellite count must be between 0 and %d", MaxSatellites)
+	case state.HDOP < 0:
+		return fmt.Errorf("HDOP cannot be negative")
+	case state.CurrentDrift < 0:
+		return fmt.Errorf("current drift cannot be negative")
+	case state.DGPSStationID < 0 || state.DGPSStationID > 1023:
+		return fmt.Errorf("DGPS station ID must be between 0 and 1023")
+	case state.DGPSAge < 0:
+		return fmt.Errorf("DGPS correction age cannot be negative")
+	}
+
+	return nil
+}
+
+// SetState atomically replaces the whole navigation state, for external
+// drivers that compute the vessel's motion themselves and use the simulator
+// only to encode and transmit it. A zero timestamp is replaced with the
+// current time. Disable physics with SetPhysicsEnabled so the simulator
+// doesn't move the vessel between updates
+func (s *Simulator) SetState(state NavigationState) error {
+	if err := ValidateState(state); err != nil {
+		return err
+	}
+
+	if state.Position.Timestamp.IsZero() {
+		state.Position.Timestamp = time.Now()
+	}
+	state.Position.Timestamp = state.Position.Timestamp.UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state.Course = s.normalizeCourse(state.Course)
+	s.state = state
+	return nil
+}
+
+// SetPhysicsEnabled sets whether the simulator moves the vessel each second.
+// With physics disabled the state only changes when set, leaving the
+// simulator as a pure encoder and transmitter
+func (s *Simulator) SetPhysicsEnabled(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.physicsDisabled = !enabled
+}
@@ -0,0 +1,81 @@
+package nmea
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxTalkerIDs is the most talker IDs a sentence can be duplicated under
+const MaxTalkerIDs = 4
+
+// ValidateTalkerIDs checks that ids is a non-empty list of distinct two-letter
+// talker IDs
+func ValidateTalkerIDs(ids []string) error {
+	if len(ids) == 0 || len(ids) > MaxTalkerIDs {
+		return fmt.Errorf("between 1 and %d talker IDs are required", MaxTalkerIDs)
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if len(id) != 2 || strings.Trim(id, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return fmt.Errorf("invalid talker ID %q: must be two uppercase letters", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate talker ID %q", id)
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// SetTalkerIDs sets the talker IDs GNSS fix sentences are sent under. Each
+// sentence is sent once per ID, e.g. both GPRMC and GNRMC, for testing
+// consumers that deduplicate or prefer a particular talker. GSA and GSV stay
+// under their constellation's talker
+func (s *Simulator) SetTalkerIDs(ids []string) error {
+	if err := ValidateTalkerIDs(ids); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.talkerIDs = append([]string(nil), ids...)
+	return nil
+}
+
+// perConstellation lists the sentences sent once per satellite system, whose
+// GP, GL, GA or GB talker identifies the constellation they describe
+var perConstellation = map[string]bool{"GSA": true, "GSV": true}
+
+// withTalkerIDs sends each GNSS fix sentence, generated with the GP talker,
+// under every configured talker ID. Satellite sentences keep their
+// constellation's talker, and other sentences such as HDG and proprietary
+// sentences keep their own talker. The caller must hold the lock
+func (s *Simulator) withTalkerIDs(sentences []string) []string {
+	if len(s.talkerIDs) == 1 && s.talkerIDs[0] == "GP" {
+		return sentences
+	}
+
+	result := make([]string, 0, len(sentences)*len(s.talkerIDs))
+	for _, sentence := range sentences {
+		if !strings.HasPrefix(sentence, "$GP") || len(sentence) < 6 || perConstellation[sentence[3:6]] {
+			result = append(result, sentence)
+			continue
+		}
+		for _, id := range s.talkerIDs {
+			result = append(result, s.retalk(sentence, id))
+		}
+	}
+
+	return result
+}
+
+// retalk returns sentence with its talker ID replaced and the checksum recomputed
+func (s *Simulator) retalk(sentence, talkerID string) string {
+	body := strings.TrimPrefix(sentence, "$")
+	if i := strings.LastIndex(body, "*"); i >= 0 {
+		body = body[:i]
+	}
+	return s.addChecksum(talkerID + body[2:])
+}
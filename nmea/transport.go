@@ -0,0 +1,105 @@
+package nmea
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// Transport delivers encoded sentences to consumers
+type Transport interface {
+	// Send writes one payload: a sentence or a batch of sentences including terminators
+	Send(data []byte) error
+	// Close releases the transport's resources
+	Close() error
+}
+
+// UDPTransport sends each payload as a UDP datagram to a unicast or multicast address
+type UDPTransport struct {
+	conn *net.UDPConn
+	addr *net.UDPAddr
+}
+
+// NewUDPTransport creates a UDP transport sending to host:port
+func NewUDPTransport(host string, port int) (*UDPTransport, error) {
+	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", host, port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve multicast address: %w", err)
+	}
+
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create UDP connection: %w", err)
+	}
+
+	return &UDPTransport{conn: conn, addr: addr}, nil
+}
+
+// Send writes data as a single datagram
+func (t *UDPTransport) Send(data []byte) error {
+	_, err := t.conn.Write(data)
+	return err
+}
+
+// Close closes the UDP socket
+func (t *UDPTransport) Close() error {
+	return t.conn.Close()
+}
+
+// SetMulticastLoopback sets whether multicast datagrams are delivered to
+// listeners on the sending host. It has no effect for unicast addresses
+func (t *UDPTransport) SetMulticastLoopback(enabled bool) error {
+	return setMulticastLoopback(t.conn, t.addr, enabled)
+}
+
+// unixSendTimeout bounds each write to a Unix socket, so a consumer that
+// stops reading can't stall transmission
+const unixSendTimeout = 2 * time.Second
+
+// UnixTransport sends each payload as a datagram over a Unix domain socket to
+// a consumer bound to a unixgram socket, for local IPC without network ports.
+// The transport sends from its own socket file, the consumer's path with a
+// ".sender" suffix, so consumers can tell the feed's source; it creates that
+// file and removes it on close. A consumer that starts late or restarts is
+// reached by the next send
+type UnixTransport struct {
+	conn *net.UnixConn
+	addr *net.UnixAddr
+	path string // the transport's own socket file
+}
+
+// NewUnixTransport creates a transport sending to the socket at path. A
+// consumer need not be listening yet
+func NewUnixTransport(path string) (*UnixTransport, error) {
+	if path == "" {
+		return nil, fmt.Errorf("socket path is required")
+	}
+
+	local := path + ".sender"
+	if err := os.Remove(local); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket %s: %w", local, err)
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: local, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Unix socket: %w", err)
+	}
+
+	return &UnixTransport{conn: conn, addr: &net.UnixAddr{Name: path, Net: "unixgram"}, path: local}, nil
+}
+
+// Send writes data as a single datagram to the consumer
+func (t *UnixTransport) Send(data []byte) error {
+	t.conn.SetWriteDeadline(time.Now().Add(unixSendTimeout))
+	_, err := t.conn.WriteToUnix(data, t.addr)
+	return err
+}
+
+// Close closes the socket and removes its file
+func (t *UnixTransport) Close() error {
+	err := t.conn.Close()
+	if removeErr := os.Remove(t.path); err == nil && !os.IsNotExist(removeErr) {
+		err = removeErr
+	}
+	return err
+}
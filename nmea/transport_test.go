@@ -0,0 +1,256 @@
+package nmea
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/net/ipv4"
+)
+
+// funcTransport sends through a function, for injecting send errors
+type funcTransport func(data []byte) error
+
+func (f funcTransport) Send(data []byte) error { return f(data) }
+func (f funcTransport) Close() error           { return nil }
+
+func TestTransmitErrorsReported(t *testing.T) {
+	refused := &net.OpError{Op: "write", Net: "udp", Err: os.NewSyscallError("write", syscall.ECONNREFUSED)}
+	missing := &net.OpError{Op: "dial", Net: "unix", Err: os.NewSyscallError("connect", syscall.ENOENT)}
+
+	tests := []struct {
+		name         string
+		err          error
+		stop         bool
+		wantNoListen bool
+		wantRunning  bool
+	}{
+		{"success", nil, false, false, true},
+		{"refused", refused, false, true, true},
+		{"refused and stop", refused, true, true, false},
+		{"socket file missing", missing, false, true, true},
+		{"other error", errors.New("network is down"), true, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSimulatorWithTransport(SimulatorConfig{TransmitRate: time.Hour},
+				funcTransport(func([]byte) error { return tt.err }))
+			if err != nil {
+				t.Fatalf("NewSimulatorWithTransport: %v", err)
+			}
+			defer s.Close()
+			s.SetStopOnNoListener(tt.stop)
+			if err := s.Start(); err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+
+			s.transmitNMEASentences()
+
+			lastErr := s.LastError()
+			if (tt.err == nil) != (lastErr == nil) {
+				t.Fatalf("LastError() = %v, want error %v", lastErr, tt.err != nil)
+			}
+			if got := errors.Is(lastErr, ErrNoListener); got != tt.wantNoListen {
+				t.Errorf("errors.Is(%v, ErrNoListener) = %v, want %v", lastErr, got, tt.wantNoListen)
+			}
+			if running := s.IsRunning(); running != tt.wantRunning {
+				t.Errorf("running = %v, want %v", running, tt.wantRunning)
+			}
+		})
+	}
+}
+
+func TestTransmitErrorClearedOnSuccess(t *testing.T) {
+	fail := true
+	s, err := NewSimulatorWithTransport(SimulatorConfig{TransmitRate: time.Hour}, funcTransport(func([]byte) error {
+		if fail {
+			return errors.New("network is down")
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("NewSimulatorWithTransport: %v", err)
+	}
+	defer s.Close()
+
+	s.transmitNMEASentences()
+	if s.LastError() == nil {
+		t.Fatal("failed transmission not reported")
+	}
+
+	fail = false
+	s.transmitNMEASentences()
+	if err := s.LastError(); err != nil {
+		t.Errorf("LastError() = %v after a successful transmission, want nil", err)
+	}
+}
+
+func TestUDPWriteToPortWithNoListener(t *testing.T) {
+	// Find a free port, then close it so nothing is listening
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skipf("no loopback UDP: %v", err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+
+	transport, err := NewUDPTransport("127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("NewUDPTransport: %v", err)
+	}
+	s, err := NewSimulatorWithTransport(SimulatorConfig{TransmitRate: time.Hour}, transport)
+	if err != nil {
+		t.Fatalf("NewSimulatorWithTransport: %v", err)
+	}
+	defer s.Close()
+
+	// The refusal arrives as an ICMP reply to an earlier datagram
+	for i := 0; i < 20; i++ {
+		s.transmitNMEASentences()
+		if errors.Is(s.LastError(), ErrNoListener) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Skipf("this system did not report the refused datagrams; last error %v", s.LastError())
+}
+
+func TestUnixTransportWithNoListener(t *testing.T) {
+	transport, err := NewUnixTransport(filepath.Join(t.TempDir(), "feed.sock"))
+	if err != nil {
+		t.Fatalf("NewUnixTransport: %v", err)
+	}
+	s, err := NewSimulatorWithTransport(SimulatorConfig{TransmitRate: time.Hour}, transport)
+	if err != nil {
+		t.Fatalf("NewSimulatorWithTransport: %v", err)
+	}
+	defer s.Close()
+
+	s.transmitNMEASentences()
+	if err := s.LastError(); !errors.Is(err, ErrNoListener) {
+		t.Errorf("LastError() = %v, want ErrNoListener", err)
+	}
+}
+
+func TestUnixTransportDelivers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feed.sock")
+	transport, err := NewUnixTransport(path)
+	if err != nil {
+		t.Fatalf("NewUnixTransport: %v", err)
+	}
+	defer transport.Close()
+
+	// The consumer starts after the transport and is reached on the next send
+	consumer, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("no Unix sockets: %v", err)
+	}
+	defer consumer.Close()
+
+	if err := transport.Send([]byte("$GPTXT*00\r\n")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	consumer.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 64)
+	n, from, err := consumer.ReadFromUnix(buf)
+	if err != nil {
+		t.Fatalf("nothing received: %v", err)
+	}
+	if got := string(buf[:n]); got != "$GPTXT*00\r\n" {
+		t.Errorf("received %q", got)
+	}
+	if from == nil || from.Name != path+".sender" {
+		t.Errorf("sent from %v, want %s", from, path+".sender")
+	}
+}
+
+func TestUnixTransportRemovesSocketOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feed.sock")
+
+	// A socket file left behind by an earlier run is replaced
+	stale, err := os.Create(path + ".sender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.Close()
+
+	transport, err := NewUnixTransport(path)
+	if err != nil {
+		t.Fatalf("NewUnixTransport: %v", err)
+	}
+	if info, err := os.Stat(path + ".sender"); err != nil || info.Mode()&os.ModeSocket == 0 {
+		t.Fatalf("transport socket file not created: %v", err)
+	}
+
+	if err := transport.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if _, err := os.Stat(path + ".sender"); !os.IsNotExist(err) {
+		t.Errorf("socket file left after close: %v", err)
+	}
+}
+
+func TestUnixTransportStalledConsumer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feed.sock")
+
+	// Bound but never read, so the consumer's queue fills
+	consumer, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("no Unix sockets: %v", err)
+	}
+	defer consumer.Close()
+
+	transport, err := NewUnixTransport(path)
+	if err != nil {
+		t.Fatalf("NewUnixTransport: %v", err)
+	}
+	defer transport.Close()
+
+	start := time.Now()
+	payload := make([]byte, 1024)
+	for i := 0; i < 100000; i++ {
+		if err = transport.Send(payload); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		t.Fatal("sends to a stalled consumer never failed")
+	}
+	if elapsed := time.Since(start); elapsed > unixSendTimeout+time.Second {
+		t.Errorf("stalled send took %s, want about %s", elapsed, unixSendTimeout)
+	}
+}
+
+func TestMulticastLoopbackDefaultsOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		disable bool
+		want    bool
+	}{
+		{"default", false, true},
+		{"disabled", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSimulator(SimulatorConfig{MulticastIP: "239.255.0.1", Port: 10110, DisableMulticastLoopback: tt.disable})
+			if err != nil {
+				t.Skipf("no multicast: %v", err)
+			}
+			defer s.Close()
+
+			loopback, err := ipv4.NewPacketConn(s.transport.(*UDPTransport).conn).MulticastLoopback()
+			if err != nil {
+				t.Fatalf("reading multicast loopback: %v", err)
+			}
+			if loopback != tt.want {
+				t.Errorf("multicast loopback %v, want %v", loopback, tt.want)
+			}
+		})
+	}
+}
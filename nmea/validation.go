@@ -0,0 +1,161 @@
+// validation.go - Running a route headless and checking it against pass/fail criteria
+package nmea
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// validationStep is the simulated time between position checks in a
+// validation run
+const validationStep = time.Second
+
+// RouteCriteria sets what a route validation run must achieve to pass
+type RouteCriteria struct {
+	Speed           float64       // knots to sail the route at
+	MaxPassDistance float64       // each waypoint must be passed within this many meters; 0 for no limit
+	MinDuration     time.Duration // the route must take at least this long; 0 for no limit
+	MaxDuration     time.Duration // the route must be completed within this long; 0 for no limit
+}
+
+// WaypointResult reports how closely the vessel passed one waypoint
+type WaypointResult struct {
+	Index           int     `json:"index"`
+	Name            string  `json:"name"`
+	ClosestApproach float64 `json:"closestApproach"` // meters
+	Passed          bool    `json:"passed"`
+}
+
+// LegResult reports how far the vessel strayed from one leg
+type LegResult struct {
+	From               int     `json:"from"`
+	To                 int     `json:"to"`
+	MaxCrossTrackError float64 `json:"maxCrossTrackError"` // meters
+}
+
+// RouteValidationReport is the outcome of a route validation run
+type RouteValidationReport struct {
+	Passed    bool             `json:"passed"`
+	Completed bool             `json:"completed"` // the final waypoint was reached
+	Duration  float64          `json:"duration"`  // simulated seconds until completion or giving up
+	Waypoints []WaypointResult `json:"waypoints"`
+	Legs      []LegResult      `json:"legs"`
+	Failures  []string         `json:"failures,omitempty"`
+}
+
+// discardTransport drops everything sent, for simulators that run headless
+type discardTransport struct{}
+
+func (discardTransport) Send([]byte) error { return nil }
+func (discardTransport) Close() error      { return nil }
+
+// RunRouteValidation sails an RTZ route headless in accelerated simulated time
+// and checks the track against criteria, reporting the closest approach to
+// each waypoint, the largest cross-track error on each leg and whether the
+// route was completed within the expected time. Nothing is transmitted
+func RunRouteValidation(config SimulatorConfig, rtzData []byte, criteria RouteCriteria) (RouteValidationReport, error) {
+	if criteria.Speed <= 0 {
+		return RouteValidationReport{}, fmt.Errorf("validation speed must be positive")
+	}
+	if criteria.MaxPassDistance < 0 || criteria.MinDuration < 0 || criteria.MaxDuration < 0 {
+		return RouteValidationReport{}, fmt.Errorf("validation criteria cannot be negative")
+	}
+	if criteria.MaxDuration > 0 && criteria.MinDuration > criteria.MaxDuration {
+		return RouteValidationReport{}, fmt.Errorf("minimum duration exceeds maximum duration")
+	}
+
+	s, err := NewSimulatorWithTransport(config, discardTransport{})
+	if err != nil {
+		return RouteValidationReport{}, err
+	}
+	defer s.Close()
+
+	if err := s.LoadRTZRoute(rtzData, criteria.Speed); err != nil {
+		return RouteValidationReport{}, err
+	}
+
+	// Give up on an unbounded run once it has taken several times as long as
+	// the route should
+	limit := criteria.MaxDuration
+	if limit == 0 {
+		hours := s.legDistance(0)/criteria.Speed*3 + 1
+		limit = time.Duration(hours * float64(time.Hour))
+	}
+
+	waypoints := s.route.Waypoints
+	report := RouteValidationReport{
+		Waypoints: make([]WaypointResult, len(waypoints)),
+		Legs:      make([]LegResult, max(len(waypoints)-1, 0)),
+	}
+	for i, wp := range waypoints {
+		report.Waypoints[i] = WaypointResult{Index: i, Name: wp.Identifier(), ClosestApproach: math.Inf(1)}
+	}
+	for i := range report.Legs {
+		report.Legs[i] = LegResult{From: i, To: i + 1}
+	}
+
+	var elapsed time.Duration
+	for {
+		s.observeValidation(&report)
+		if s.routeComplete || elapsed >= limit {
+			break
+		}
+		s.Step(validationStep)
+		elapsed += validationStep
+	}
+
+	report.Completed = s.routeComplete
+	report.Duration = elapsed.Seconds()
+	criteria.judge(&report, elapsed)
+	return report, nil
+}
+
+// observeValidation records the vessel's current distance from each
+// waypoint and its cross-track error on the leg being sailed
+func (s *Simulator) observeValidation(report *RouteValidationReport) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pos := s.state.Position
+	for i, wp := range s.route.Waypoints {
+		distance := s.calculateDistance(pos.Latitude, pos.Longitude, wp.Latitude, wp.Longitude) * metersPerNauticalMile
+		report.Waypoints[i].ClosestApproach = math.Min(report.Waypoints[i].ClosestApproach, distance)
+	}
+
+	// Cross-track error is only steered against once on a leg proper
+	if s.autoNavigate && s.steering.Active && s.currentWaypoint > 0 {
+		leg := &report.Legs[s.currentWaypoint-1]
+		xte := math.Abs(s.steering.CrossTrackError) * metersPerNauticalMile
+		leg.MaxCrossTrackError = math.Max(leg.MaxCrossTrackError, xte)
+	}
+}
+
+// judge marks each waypoint and the whole run as passed or failed, listing
+// the criteria that were not met
+func (c RouteCriteria) judge(report *RouteValidationReport, elapsed time.Duration) {
+	var failures []string
+
+	if !report.Completed {
+		failures = append(failures, fmt.Sprintf("route not completed within %s", elapsed))
+	} else {
+		if c.MinDuration > 0 && elapsed < c.MinDuration {
+			failures = append(failures, fmt.Sprintf("route completed in %s, faster than the minimum %s", elapsed, c.MinDuration))
+		}
+		if c.MaxDuration > 0 && elapsed > c.MaxDuration {
+			failures = append(failures, fmt.Sprintf("route completed in %s, slower than the maximum %s", elapsed, c.MaxDuration))
+		}
+	}
+
+	for i := range report.Waypoints {
+		wp := &report.Waypoints[i]
+		wp.Passed = c.MaxPassDistance == 0 || wp.ClosestApproach <= c.MaxPassDistance
+		if !wp.Passed {
+			failures = append(failures, fmt.Sprintf("waypoint %d (%s) passed at %.0f m, beyond %.0f m",
+				wp.Index, wp.Name, wp.ClosestApproach, c.MaxPassDistance))
+		}
+	}
+
+	report.Failures = failures
+	report.Passed = len(failures) == 0
+}
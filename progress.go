@@ -0,0 +1,130 @@
+// progress.go - Saving and resuming progress along a long route
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"route-sim/nmea"
+)
+
+// RouteProgress records how far along its route the vessel has got, so a long
+// run can be resumed after the app restarts
+type RouteProgress struct {
+	RouteName       string    `json:"routeName,omitempty"`
+	RouteHash       string    `json:"routeHash"` // identifies the route the progress belongs to
+	CurrentWaypoint int       `json:"currentWaypoint"`
+	Position        Position  `json:"position"`
+	Speed           float64   `json:"speed"`
+	Course          float64   `json:"course"`
+	SavedAt         time.Time `json:"savedAt"`
+}
+
+// routeHash fingerprints a route's waypoints, so progress is only resumed on
+// the route it was saved from
+func routeHash(route *nmea.RTZRoute) string {
+	hash := sha256.New()
+	for _, wp := range route.Waypoints {
+		fmt.Fprintf(hash, "%s,%.7f,%.7f;", wp.ID, wp.Latitude, wp.Longitude)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// SaveProgress writes the current waypoint and position along the loaded
+// route to a file, for ResumeFromProgress to pick up later
+func (a *App) SaveProgress(path string) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	if a.mode != "rtz" {
+		return fmt.Errorf("route progress only available in RTZ mode")
+	}
+
+	route := a.simulator.GetRoute()
+	if route == nil {
+		return nmea.ErrNoRoute
+	}
+
+	state := a.simulator.GetCurrentState()
+	progress := RouteProgress{
+		RouteName:       route.Name,
+		RouteHash:       routeHash(route),
+		CurrentWaypoint: a.simulator.GetCurrentWaypoint(),
+		Position: Position{
+			Latitude:  state.Position.Latitude,
+			Longitude: state.Position.Longitude,
+			Timestamp: state.Position.Timestamp,
+		},
+		Speed:   state.Speed,
+		Course:  state.Course,
+		SavedAt: time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode progress: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write progress file: %w", err)
+	}
+	return nil
+}
+
+// ResumeFromProgress restores the waypoint and position saved by SaveProgress.
+// The same route must already be loaded, so load it before resuming
+func (a *App) ResumeFromProgress(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read progress file: %w", err)
+	}
+
+	var progress RouteProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return fmt.Errorf("invalid progress file: %w", err)
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return nmea.ErrNotRunning
+	}
+
+	if a.mode != "rtz" {
+		return fmt.Errorf("route progress only available in RTZ mode")
+	}
+
+	route := a.simulator.GetRoute()
+	if route == nil {
+		return nmea.ErrNoRoute
+	}
+
+	if routeHash(route) != progress.RouteHash {
+		return fmt.Errorf("progress was saved for a different route %q", progress.RouteName)
+	}
+
+	pos := progress.Position
+
+	// Progress saved while still approaching the start resumes that approach
+	if progress.CurrentWaypoint == 0 {
+		a.simulator.SetPosition(pos.Latitude, pos.Longitude, progress.Speed, progress.Course)
+		return a.simulator.SetRouteFromPosition(route, progress.Speed)
+	}
+
+	if err := a.simulator.SetCurrentWaypoint(progress.CurrentWaypoint); err != nil {
+		return err
+	}
+
+	// Waypoint selection moves the vessel, so restore the exact position
+	a.simulator.SetPosition(pos.Latitude, pos.Longitude, progress.Speed, progress.Course)
+	return nil
+}
@@ -0,0 +1,80 @@
+// rtzsource.go - Loading RTZ routes from local files or remote URLs
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// rtzDownloadTimeout bounds the whole request, including reading the body
+	rtzDownloadTimeout = 15 * time.Second
+	// maxRTZDownloadSize guards against pointing the loader at something huge
+	maxRTZDownloadSize = 10 << 20
+)
+
+// rtzContentTypes lists the content types accepted for downloaded routes.
+// RTZ is XML, but servers commonly serve unknown extensions as octet-stream
+var rtzContentTypes = map[string]bool{
+	"application/xml":          true,
+	"text/xml":                 true,
+	"application/rtz+xml":      true,
+	"application/octet-stream": true,
+	"text/plain":               true,
+}
+
+// isRTZURL reports whether source refers to an http(s) URL rather than a file
+func isRTZURL(source string) bool {
+	lower := strings.ToLower(source)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// readRTZSource reads RTZ data from a file path or an http(s) URL
+func readRTZSource(source string) ([]byte, error) {
+	if isRTZURL(source) {
+		return downloadRTZ(source)
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RTZ file: %w", err)
+	}
+	return data, nil
+}
+
+// downloadRTZ fetches an RTZ route over HTTP
+func downloadRTZ(url string) ([]byte, error) {
+	client := &http.Client{Timeout: rtzDownloadTimeout}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download RTZ route: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download RTZ route: server returned %s", resp.Status)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !rtzContentTypes[mediaType] {
+			return nil, fmt.Errorf("URL does not point to an RTZ route (content type %q)", contentType)
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRTZDownloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download RTZ route: %w", err)
+	}
+	if len(data) > maxRTZDownloadSize {
+		return nil, fmt.Errorf("RTZ route exceeds maximum size of %d MB", maxRTZDownloadSize>>20)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,258 @@
+// snapshot.go - Export and import of the simulation scenario
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"route-sim/nmea"
+)
+
+// Snapshot captures settings and simulation state so a scenario can be saved,
+// shared and resumed
+type Snapshot struct {
+	Mode            string           `json:"mode"`
+	IsRunning       bool             `json:"isRunning"`
+	Settings        SnapshotSettings `json:"settings"`
+	MagneticVar     float64          `json:"magneticVar"`
+	Position        Position         `json:"position"`
+	Speed           float64          `json:"speed"`
+	Course          float64          `json:"course"`
+	Route           *RTZRoute        `json:"route,omitempty"`
+	CurrentWaypoint int              `json:"currentWaypoint"`
+}
+
+// SnapshotSettings holds the app settings a simulation is created from
+type SnapshotSettings struct {
+	LineTerminator           string   `json:"lineTerminator"`
+	AcquisitionDelay         int      `json:"acquisitionDelay"` // seconds
+	StartProfile             string   `json:"startProfile"`
+	BatchDatagram            bool     `json:"batchDatagram"`
+	PositionPrecision        int      `json:"positionPrecision"`
+	DisableMulticastLoopback bool     `json:"disableMulticastLoopback"`
+	SocketPath               string   `json:"socketPath"`
+	AltitudeUnit             string   `json:"altitudeUnit"`
+	RouteAtStart             bool     `json:"routeAtStart"`
+	RouteInterval            int      `json:"routeInterval"` // seconds
+	BlankCourse              bool     `json:"blankCourse"`
+	StrictASCII              bool     `json:"strictASCII"`
+	LowercaseChecksum        bool     `json:"lowercaseChecksum"`
+	AutoSwapLatLon           bool     `json:"autoSwapLatLon"`
+	SpeedRamp                int      `json:"speedRamp"`    // seconds
+	TransmitMode             string   `json:"transmitMode"` // "" for the default
+	Heartbeat                int      `json:"heartbeat"`    // seconds
+	TalkerIDs                []string `json:"talkerIds"`
+	StopOnNoListener         bool     `json:"stopOnNoListener"`
+	NMEAVersion              string   `json:"nmeaVersion"`
+}
+
+// snapshotSettings returns the app settings simulations are created from.
+// The caller must hold the lock
+func (a *App) snapshotSettings() SnapshotSettings {
+	return SnapshotSettings{
+		LineTerminator:           a.lineTerminator,
+		AcquisitionDelay:         int(a.acquisitionDelay / time.Second),
+		StartProfile:             a.startProfile,
+		BatchDatagram:            a.batchDatagram,
+		PositionPrecision:        a.positionPrecision,
+		DisableMulticastLoopback: a.loopbackDisabled,
+		SocketPath:               a.socketPath,
+		AltitudeUnit:             a.altitudeUnit,
+		RouteAtStart:             a.routeAtStart,
+		RouteInterval:            int(a.routeInterval / time.Second),
+		BlankCourse:              a.blankCourse,
+		StrictASCII:              a.strictASCII,
+		LowercaseChecksum:        a.lowercaseChecksum,
+		AutoSwapLatLon:           a.autoSwapLatLon,
+		SpeedRamp:                int(a.speedRamp / time.Second),
+		TransmitMode:             a.transmitMode,
+		Heartbeat:                int(a.heartbeat / time.Second),
+		TalkerIDs:                append([]string(nil), a.talkerIDs...),
+		StopOnNoListener:         a.stopOnNoListener,
+		NMEAVersion:              a.nmeaVersion,
+	}
+}
+
+// validate checks the settings as the individual App setters would
+func (s SnapshotSettings) validate() error {
+	if err := nmea.ValidateLineTerminator(s.LineTerminator); err != nil {
+		return err
+	}
+	if s.AcquisitionDelay < 0 {
+		return fmt.Errorf("acquisition delay cannot be negative")
+	}
+	if err := nmea.ValidateStartProfile(s.StartProfile); err != nil {
+		return err
+	}
+	if s.PositionPrecision < nmea.MinPositionPrecision || s.PositionPrecision > nmea.MaxPositionPrecision {
+		return fmt.Errorf("position precision must be between %d and %d",
+			nmea.MinPositionPrecision, nmea.MaxPositionPrecision)
+	}
+	if err := nmea.ValidateAltitudeUnit(s.AltitudeUnit); err != nil {
+		return err
+	}
+	if s.RouteInterval < 0 {
+		return fmt.Errorf("route broadcast interval cannot be negative")
+	}
+	if err := nmea.ValidateSpeedRamp(time.Duration(s.SpeedRamp) * time.Second); err != nil {
+		return err
+	}
+	if s.TransmitMode != "" {
+		if err := nmea.ValidateTransmitMode(s.TransmitMode); err != nil {
+			return err
+		}
+	}
+	if s.Heartbeat < 0 {
+		return fmt.Errorf("heartbeat cannot be negative")
+	}
+	if err := nmea.ValidateTalkerIDs(s.TalkerIDs); err != nil {
+		return err
+	}
+	return nmea.ValidateNMEAVersion(s.NMEAVersion)
+}
+
+// applySettings replaces the app settings simulations are created from. The
+// caller must hold the write lock
+func (a *App) applySettings(s SnapshotSettings) {
+	a.lineTerminator = s.LineTerminator
+	a.acquisitionDelay = time.Duration(s.AcquisitionDelay) * time.Second
+	a.startProfile = s.StartProfile
+	a.batchDatagram = s.BatchDatagram
+	a.positionPrecision = s.PositionPrecision
+	a.loopbackDisabled = s.DisableMulticastLoopback
+	a.socketPath = s.SocketPath
+	a.altitudeUnit = s.AltitudeUnit
+	a.routeAtStart = s.RouteAtStart
+	a.routeInterval = time.Duration(s.RouteInterval) * time.Second
+	a.blankCourse = s.BlankCourse
+	a.strictASCII = s.StrictASCII
+	a.lowercaseChecksum = s.LowercaseChecksum
+	a.autoSwapLatLon = s.AutoSwapLatLon
+	a.speedRamp = time.Duration(s.SpeedRamp) * time.Second
+	a.transmitMode = s.TransmitMode
+	a.heartbeat = time.Duration(s.Heartbeat) * time.Second
+	a.talkerIDs = append([]string(nil), s.TalkerIDs...)
+	a.stopOnNoListener = s.StopOnNoListener
+	a.nmeaVersion = s.NMEAVersion
+}
+
+// ExportSnapshot returns the current settings and simulation state as JSON
+func (a *App) ExportSnapshot() (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.simulator == nil {
+		return "", nmea.ErrNotRunning
+	}
+
+	state := a.simulator.GetCurrentState()
+	snapshot := Snapshot{
+		Mode:        a.mode,
+		IsRunning:   a.isRunning,
+		Settings:    a.snapshotSettings(),
+		MagneticVar: state.MagneticVar,
+		Position: Position{
+			Latitude:  state.Position.Latitude,
+			Longitude: state.Position.Longitude,
+			Timestamp: state.Position.Timestamp,
+		},
+		Speed:           state.Speed,
+		Course:          state.Course,
+		CurrentWaypoint: a.simulator.GetCurrentWaypoint(),
+	}
+
+	if route := a.simulator.GetRoute(); route != nil {
+		snapshot.Route = routeToJSON(route)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// ImportSnapshot restores settings and simulation state from JSON produced by
+// ExportSnapshot, restarting the simulation if it was running when exported
+func (a *App) ImportSnapshot(snapshotJSON string) error {
+	var snapshot Snapshot
+	if err := json.Unmarshal([]byte(snapshotJSON), &snapshot); err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+
+	if snapshot.Mode != "manual" && snapshot.Mode != "rtz" {
+		return fmt.Errorf("invalid snapshot mode %q", snapshot.Mode)
+	}
+
+	if err := snapshot.Settings.validate(); err != nil {
+		return fmt.Errorf("invalid snapshot settings: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Replace any existing simulation
+	if a.simulator != nil {
+		a.simulator.Close()
+	}
+	a.isRunning = false
+
+	a.applySettings(snapshot.Settings)
+
+	var err error
+	a.simulator, err = nmea.NewSimulator(a.simulatorConfig(snapshot.MagneticVar))
+	if err != nil {
+		return fmt.Errorf("failed to create simulator: %w", err)
+	}
+
+	pos := snapshot.Position
+	a.simulator.SetPosition(pos.Latitude, pos.Longitude, snapshot.Speed, snapshot.Course)
+
+	if snapshot.Route != nil {
+		route := &nmea.RTZRoute{
+			Name:       snapshot.Route.Name,
+			VesselName: snapshot.Route.VesselName,
+			VesselIMO:  snapshot.Route.VesselIMO,
+			Version:    snapshot.Route.Version,
+			Waypoints:  make([]nmea.Waypoint, len(snapshot.Route.Waypoints)),
+		}
+		for i, wp := range snapshot.Route.Waypoints {
+			route.Waypoints[i] = nmea.Waypoint{
+				ID:           wp.ID,
+				Name:         wp.Name,
+				Latitude:     wp.Latitude,
+				Longitude:    wp.Longitude,
+				SteeringMode: wp.SteeringMode,
+				Radius:       wp.Radius,
+			}
+		}
+
+		if snapshot.CurrentWaypoint == 0 {
+			err = a.simulator.SetRouteFromPosition(route, snapshot.Speed)
+		} else {
+			err = a.simulator.SetRoute(route, snapshot.Speed)
+			if err == nil {
+				err = a.simulator.SetCurrentWaypoint(snapshot.CurrentWaypoint)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore route: %w", err)
+		}
+
+		// Waypoint selection moves the vessel, so restore the exact position
+		a.simulator.SetPosition(pos.Latitude, pos.Longitude, snapshot.Speed, snapshot.Course)
+	}
+
+	a.mode = snapshot.Mode
+
+	if snapshot.IsRunning {
+		if err := a.simulator.Start(); err != nil {
+			return fmt.Errorf("failed to start simulator: %w", err)
+		}
+		a.isRunning = true
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+// validation.go - Route validation runs for automated testing
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"route-sim/nmea"
+)
+
+// RouteValidationConfig describes a route validation run and its pass/fail
+// criteria
+type RouteValidationConfig struct {
+	FilePath        string  `json:"filePath"`        // local path or http(s) URL of the RTZ route
+	Speed           float64 `json:"speed"`           // knots
+	MaxPassDistance float64 `json:"maxPassDistance"` // meters each waypoint must be passed within; 0 for no limit
+	MinMinutes      float64 `json:"minMinutes"`      // shortest expected time to complete; 0 for no limit
+	MaxMinutes      float64 `json:"maxMinutes"`      // longest expected time to complete; 0 for no limit
+}
+
+// RunRouteValidation sails a route headless in accelerated time with the
+// current settings and reports whether it met the criteria, for using the
+// simulator as a test oracle. The running simulation, if any, is unaffected
+func (a *App) RunRouteValidation(config RouteValidationConfig) (nmea.RouteValidationReport, error) {
+	rtzData, err := readRTZSource(config.FilePath)
+	if err != nil {
+		return nmea.RouteValidationReport{}, err
+	}
+
+	a.mu.RLock()
+	simConfig := a.simulatorConfig(-3.0)
+	a.mu.RUnlock()
+
+	criteria := nmea.RouteCriteria{
+		Speed:           config.Speed,
+		MaxPassDistance: config.MaxPassDistance,
+		MinDuration:     time.Duration(config.MinMinutes * float64(time.Minute)),
+		MaxDuration:     time.Duration(config.MaxMinutes * float64(time.Minute)),
+	}
+
+	report, err := nmea.RunRouteValidation(simConfig, rtzData, criteria)
+	if err != nil {
+		return nmea.RouteValidationReport{}, fmt.Errorf("route validation failed to run: %w", err)
+	}
+
+	return report, nil
+}